@@ -0,0 +1,255 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SinkStatus describes one output of a logger as reported by LevelHandler.
+type SinkStatus struct {
+	Index      int        `json:"index"`
+	Output     string     `json:"output"`    // "stdout" or the file path
+	Levels     string     `json:"levels"`    // eg. "INFO,WARN,ERROR"
+	ApiLevels  string     `json:"apiLevels"` // eg. "INFO,WARN,ERROR"
+	Structured bool       `json:"structured"`
+	Json       bool       `json:"json"`
+	Format     string     `json:"format,omitempty"`    // "logfmt" or "ecs"
+	RestoreAt  *time.Time `json:"restoreAt,omitempty"` // when a temporary level change is reverted
+}
+
+// LevelChange is the body accepted by LevelHandler on PUT and POST. The same fields may be passed as
+// query parameters instead.
+type LevelChange struct {
+	Sink      *int   `json:"sink,omitempty"` // index of the sink to change; all sinks when omitted
+	Levels    string `json:"levels"`         // eg. "debug|info|warning|error"; empty leaves levels unchanged
+	ApiLevels string `json:"apiLevels"`      // eg. "info|error"; empty leaves API levels unchanged
+	TTL       string `json:"ttl"`            // eg. "10m"; when set, the previous levels are restored afterwards
+}
+
+// levelHandler serves LevelHandler and remembers which sinks have a temporary level change pending.
+type levelHandler struct {
+	logger *modernLogger
+
+	mu      sync.Mutex
+	pending map[int]*levelRestore
+}
+
+// levelRestore holds the levels a sink had before a temporary change. They are kept as parsed, so
+// any level set, including DISABLED, is restored exactly.
+type levelRestore struct {
+	levels    []LogLevel
+	apiLevels []LogLevel
+	at        time.Time
+	timer     *time.Timer
+}
+
+// LevelHandler returns an http.Handler for viewing and changing log levels at runtime.
+//
+// GET responds with the status of every sink. PUT or POST changes levels, for example to turn on
+// DEBUG for ten minutes:
+//
+//	curl -X PUT localhost:8080/debug/levels -d '{"levels":"debug|info|warning|error","ttl":"10m"}'
+//	curl -X PUT 'localhost:8080/debug/levels?sink=1&levels=debug&ttl=10m'
+//
+// Mount it on an internal-only listener; it lets anyone who can reach it change logging.
+func LevelHandler(logger Logger) http.Handler {
+	ml, _ := logger.(*modernLogger)
+	return &levelHandler{logger: ml, pending: map[int]*levelRestore{}}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		change, err := decodeLevelChange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.apply(change); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Sinks []SinkStatus `json:"sinks"`
+	}{h.status()}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// decodeLevelChange reads a LevelChange from the JSON body, or from query parameters when the body is empty.
+func decodeLevelChange(r *http.Request) (LevelChange, error) {
+	var change LevelChange
+	if r.Body != nil {
+		// an empty body, chunked or not, means the change is in the query
+		err := json.NewDecoder(r.Body).Decode(&change)
+		if err == nil {
+			return change, nil
+		}
+		if !errors.Is(err, io.EOF) {
+			return change, fmt.Errorf("invalid body: %w", err)
+		}
+	}
+
+	query := r.URL.Query()
+	change.Levels = query.Get("levels")
+	change.ApiLevels = query.Get("apiLevels")
+	change.TTL = query.Get("ttl")
+	if sink := query.Get("sink"); sink != "" {
+		index, err := strconv.Atoi(sink)
+		if err != nil {
+			return change, fmt.Errorf("invalid sink: %s", sink)
+		}
+		change.Sink = &index
+	}
+	return change, nil
+}
+
+// apply performs a level change and schedules the restore of the previous levels when a TTL is given.
+func (h *levelHandler) apply(change LevelChange) error {
+	if h.logger == nil {
+		return fmt.Errorf("logger does not support level changes")
+	}
+	if change.Levels == "" && change.ApiLevels == "" {
+		return fmt.Errorf("levels or apiLevels is required")
+	}
+	var ttl time.Duration
+	if change.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(change.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid ttl: %s", change.TTL)
+		}
+	}
+	index := -1
+	if change.Sink != nil {
+		index = *change.Sink
+		if index < 0 {
+			return fmt.Errorf("invalid sink: %d", index)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	previous := h.logger.sinkLevels()
+	if err := h.logger.setSinkLevels(index, change.Levels, change.ApiLevels); err != nil {
+		return err
+	}
+
+	for i, sink := range previous {
+		if index >= 0 && i != index {
+			continue
+		}
+		restore := h.pending[i]
+		if restore != nil {
+			restore.timer.Stop()
+			delete(h.pending, i)
+		}
+		if ttl == 0 {
+			// a permanent change overrides any pending restore
+			continue
+		}
+		if restore == nil {
+			// keep the levels from before the first temporary change
+			restore = &levelRestore{levels: sink[0], apiLevels: sink[1]}
+		}
+		h.schedule(i, restore, ttl)
+	}
+	return nil
+}
+
+// schedule restores the sink's previous levels after ttl. Caller must hold h.mu.
+func (h *levelHandler) schedule(index int, restore *levelRestore, ttl time.Duration) {
+	restore.at = time.Now().Add(ttl)
+	restore.timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if h.pending[index] != restore {
+			return
+		}
+		delete(h.pending, index)
+		if err := h.logger.restoreSinkLevels(index, restore.levels, restore.apiLevels); err != nil {
+			h.logger.Errorf("failed to restore log levels of sink %d: %v", index, err)
+		}
+	})
+	h.pending[index] = restore
+}
+
+// status returns the sinks of the logger together with pending restores.
+func (h *levelHandler) status() []SinkStatus {
+	if h.logger == nil {
+		return []SinkStatus{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sinks := h.logger.sinkStatuses()
+	for i := range sinks {
+		if restore := h.pending[sinks[i].Index]; restore != nil {
+			at := restore.at
+			sinks[i].RestoreAt = &at
+		}
+	}
+	return sinks
+}
+
+// sinkStatuses describes every sink of the logger.
+func (ml *modernLogger) sinkStatuses() []SinkStatus {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	sinks := make([]SinkStatus, 0, len(ml.configs))
+	for i, config := range ml.configs {
+		output := config.FilePath
+		if config.Stdout {
+			output = "stdout"
+		}
+		sinks = append(sinks, SinkStatus{
+			Index:      i,
+			Output:     output,
+			Levels:     formatLevels(config.Levels),
+			ApiLevels:  formatLevels(config.ApiLevels),
+			Structured: config.Structured,
+			Json:       config.Json,
+			Format:     config.Format,
+		})
+	}
+	return sinks
+}
+
+// sinkLevels returns the levels and API levels of every sink.
+func (ml *modernLogger) sinkLevels() [][2][]LogLevel {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	levels := make([][2][]LogLevel, len(ml.configs))
+	for i, config := range ml.configs {
+		levels[i] = [2][]LogLevel{slices.Clone(config.Levels), slices.Clone(config.ApiLevels)}
+	}
+	return levels
+}
+
+// formatLevels is the inverse of parseLevels.
+func formatLevels(levels []LogLevel) string {
+	names := make([]string, len(levels))
+	for i, level := range levels {
+		names[i] = strings.TrimSpace(levelToString(level))
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelHandler(t *testing.T) {
+	logger, err := NewLogger(JsonConfig{Levels: "INFO,ERROR", ApiLevels: "ERROR", NoColors: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	ml := logger.(*modernLogger)
+	if err := ml.addConfig(JsonConfig{Levels: "WARNING", Json: true}); err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+	handler := LevelHandler(logger)
+
+	serve := func(method, target, body string) (int, []SinkStatus) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var resp struct {
+			Sinks []SinkStatus `json:"sinks"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Invalid JSON response %q: %v", rec.Body.String(), err)
+			}
+		}
+		return rec.Code, resp.Sinks
+	}
+
+	code, sinks := serve(http.MethodGet, "/levels", "")
+	if code != http.StatusOK || len(sinks) != 2 {
+		t.Fatalf("Expected 2 sinks from GET, got %d: %+v", code, sinks)
+	}
+	if sinks[0].Output != "stdout" || sinks[0].Levels != "INFO,ERROR" || sinks[0].ApiLevels != "ERROR" {
+		t.Errorf("Unexpected status for sink 0: %+v", sinks[0])
+	}
+	if !sinks[1].Json || sinks[1].Levels != "WARN" {
+		t.Errorf("Unexpected status for sink 1: %+v", sinks[1])
+	}
+
+	// temporary change of one sink via query parameters
+	code, sinks = serve(http.MethodPut, "/levels?sink=1&levels=debug&ttl=50ms", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected PUT to succeed, got %d", code)
+	}
+	if sinks[1].Levels != "DEBUG" || sinks[1].RestoreAt == nil {
+		t.Errorf("Expected sink 1 at DEBUG with a pending restore, got %+v", sinks[1])
+	}
+	if sinks[0].Levels != "INFO,ERROR" || sinks[0].RestoreAt != nil {
+		t.Errorf("Expected sink 0 to be unchanged, got %+v", sinks[0])
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for ml.sinkStatuses()[1].Levels != "WARN" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ml.sinkStatuses()[1].Levels; got != "WARN" {
+		t.Errorf("Expected sink 1 levels to be restored to WARN after the TTL, got %s", got)
+	}
+
+	// permanent change of every sink via JSON body
+	code, sinks = serve(http.MethodPost, "/levels", `{"levels":"debug|info","apiLevels":"info"}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected POST to succeed, got %d", code)
+	}
+	for _, sink := range sinks {
+		if sink.Levels != "DEBUG,INFO" || sink.ApiLevels != "INFO" {
+			t.Errorf("Expected every sink to be changed, got %+v", sink)
+		}
+	}
+
+	// a chunked request with an empty body falls back to the query
+	req := httptest.NewRequest(http.MethodPut, "/levels?levels=info", strings.NewReader(""))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || ml.sinkStatuses()[0].Levels != "INFO" {
+		t.Errorf("Expected the query of a chunked empty request to apply, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// a disabled sink is disabled again after a temporary change
+	if err := ml.addConfig(JsonConfig{Levels: "DISABLED"}); err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+	code, sinks = serve(http.MethodGet, "/levels", "")
+	if code != http.StatusOK || sinks[2].Levels != "DISABLED" {
+		t.Fatalf("Expected sink 2 to report DISABLED, got %d: %+v", code, sinks)
+	}
+	if code, _ = serve(http.MethodPut, "/levels?sink=2&levels=debug&ttl=50ms", ""); code != http.StatusOK {
+		t.Fatalf("Expected PUT to succeed, got %d", code)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for ml.sinkStatuses()[2].Levels != "DISABLED" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := ml.sinkStatuses()[2]; status.Levels != "DISABLED" || !ml.configs[2].Disabled {
+		t.Errorf("Expected sink 2 to be disabled again after the TTL, got %+v", status)
+	}
+
+	for _, tc := range []struct{ method, target, body string }{
+		{http.MethodPut, "/levels", `{"levels":"loud"}`},
+		{http.MethodPut, "/levels", `{"levels":"debug","ttl":"soon"}`},
+		{http.MethodPut, "/levels?sink=7&levels=debug", ""},
+		{http.MethodPut, "/levels", ""},
+	} {
+		if code, _ := serve(tc.method, tc.target, tc.body); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s %s %s, got %d", tc.method, tc.target, tc.body, code)
+		}
+	}
+	if code, _ := serve(http.MethodDelete, "/levels", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for DELETE, got %d", code)
+	}
+}
@@ -0,0 +1,343 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Access log formats accepted in JsonConfig.ApiFormat
+const (
+	apiFormatCommon   = "common"
+	apiFormatCombined = "combined"
+)
+
+// clfTimeLayout is the timestamp layout of the Common Log Format.
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// httpRequestKey marks the context of the access record Middleware logs for a request.
+type httpRequestKey struct{}
+
+// httpRequest describes the request behind an API record, as captured by Middleware.
+type httpRequest struct {
+	remoteAddr string
+	user       string
+	method     string
+	proto      string
+	referer    string
+	userAgent  string
+	bytes      int64
+	start      time.Time
+	duration   time.Duration
+}
+
+func withHTTPRequest(ctx context.Context, request *httpRequest) context.Context {
+	return context.WithValue(ctx, httpRequestKey{}, request)
+}
+
+func httpRequestFrom(ctx context.Context) *httpRequest {
+	if ctx == nil {
+		return nil
+	}
+	request, _ := ctx.Value(httpRequestKey{}).(*httpRequest)
+	return request
+}
+
+// StatusLevel maps the HTTP status codes Min through Max to the level API records are logged at.
+type StatusLevel struct {
+	Min   int
+	Max   int
+	Level LogLevel
+}
+
+// apiLevel returns the level an API record with statusCode is logged at on this sink: the narrowest
+// matching ApiStatusLevels entry, or the default mapping (>=500 ERROR, 305-499 WARNING, else INFO).
+func (c *LoggerConfig) apiLevel(statusCode int) LogLevel {
+	for _, mapping := range c.ApiStatusLevels {
+		if statusCode >= mapping.Min && statusCode <= mapping.Max {
+			return mapping.Level
+		}
+	}
+	level, _ := getAPILevelAndColor(statusCode)
+	return level
+}
+
+// SlowPath is a latency threshold for API records whose request path matches Pattern.
+type SlowPath struct {
+	Pattern   *regexp.Regexp
+	Threshold time.Duration
+}
+
+// apiRecordLevel returns the level an API record is logged at on this sink: the level of its status
+// code, raised one step when the request was slow.
+func (c *LoggerConfig) apiRecordLevel(api *apiRecord) LogLevel {
+	level := c.apiLevel(api.statusCode)
+	if c.isSlow(api) {
+		level = escalateLevel(level)
+	}
+	return level
+}
+
+// isSlow reports whether an API record's duration exceeds the sink's threshold for its path: the
+// lowest threshold of the matching ApiSlowPaths, or ApiSlowThreshold when none match.
+func (c *LoggerConfig) isSlow(api *apiRecord) bool {
+	if api.duration <= 0 {
+		return false
+	}
+	threshold := c.ApiSlowThreshold
+	matched := false
+	for _, slowPath := range c.ApiSlowPaths {
+		if slowPath.Pattern.MatchString(api.requestPath) && (!matched || slowPath.Threshold < threshold) {
+			threshold = slowPath.Threshold
+			matched = true
+		}
+	}
+	return threshold > 0 && api.duration > threshold
+}
+
+// escalateLevel returns the next built-in level above level, up to ERROR. Levels at least as severe
+// as ERROR, such as FATAL, are returned unchanged.
+func escalateLevel(level LogLevel) LogLevel {
+	switch severity := toSlogLevel(level); {
+	case severity >= slog.LevelError:
+		return level
+	case severity < slog.LevelDebug:
+		return DEBUG
+	case severity < slog.LevelInfo:
+		return INFO
+	case severity < slog.LevelWarn:
+		return WARNING
+	default:
+		return ERROR
+	}
+}
+
+// apiDuration returns how long the request behind an API record took: as measured by Middleware,
+// or passed as a time.Duration "duration" argument to API and friends. The args of formatted calls
+// such as APIf are format arguments, not key-value pairs, so they are not searched.
+func apiDuration(request *httpRequest, formatted bool, args []any) time.Duration {
+	if request != nil {
+		return request.duration
+	}
+	if formatted {
+		return 0
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		if key, ok := args[i].(string); ok && key == "duration" {
+			if duration, ok := args[i+1].(time.Duration); ok {
+				return duration
+			}
+		}
+	}
+	return 0
+}
+
+// parseSlowThresholds parses JsonConfig.ApiSlowThreshold and ApiSlowPaths.
+func parseSlowThresholds(threshold string, paths map[string]string) (time.Duration, []SlowPath, error) {
+	var defaultThreshold time.Duration
+	if threshold != "" {
+		parsed, err := time.ParseDuration(threshold)
+		if err != nil || parsed < 0 {
+			return 0, nil, fmt.Errorf("invalid apiSlowThreshold: %s", threshold)
+		}
+		defaultThreshold = parsed
+	}
+	var slowPaths []SlowPath
+	for pattern, value := range paths {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid apiSlowPaths regex: %w", err)
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return 0, nil, fmt.Errorf("invalid apiSlowPaths threshold for %s: %s", pattern, value)
+		}
+		slowPaths = append(slowPaths, SlowPath{Pattern: re, Threshold: parsed})
+	}
+	return defaultThreshold, slowPaths, nil
+}
+
+// parseStatusLevels parses JsonConfig.ApiStatusLevels: keys are a status code ("404"), a class
+// ("4xx") or a range ("400-499"), values a single level name. The result is ordered narrowest first,
+// so specific codes win over the classes and ranges they fall in.
+func parseStatusLevels(statusLevels map[string]string) ([]StatusLevel, error) {
+	var parsed []StatusLevel
+	for key, value := range statusLevels {
+		minCode, maxCode, err := parseStatusRange(key)
+		if err != nil {
+			return nil, err
+		}
+		levels, err := parseLevels(value, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(levels) != 1 || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("invalid apiStatusLevels level for %s: %s", key, value)
+		}
+		parsed = append(parsed, StatusLevel{Min: minCode, Max: maxCode, Level: levels[0]})
+	}
+	slices.SortFunc(parsed, func(a, b StatusLevel) int {
+		if width := (a.Max - a.Min) - (b.Max - b.Min); width != 0 {
+			return width
+		}
+		return a.Min - b.Min
+	})
+	return parsed, nil
+}
+
+// parseStatusRange parses "404", "4xx" or "400-499" into an inclusive range of status codes.
+func parseStatusRange(key string) (int, int, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	invalid := fmt.Errorf("invalid apiStatusLevels status: %s", key)
+
+	if len(key) == 3 && strings.HasSuffix(key, "xx") {
+		class, err := strconv.Atoi(key[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, invalid
+		}
+		return class * 100, class*100 + 99, nil
+	}
+	low, high, isRange := strings.Cut(key, "-")
+	minCode, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return 0, 0, invalid
+	}
+	maxCode := minCode
+	if isRange {
+		if maxCode, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+			return 0, 0, invalid
+		}
+	}
+	if minCode < 100 || maxCode > 599 || minCode > maxCode {
+		return 0, 0, invalid
+	}
+	return minCode, maxCode, nil
+}
+
+// parseApiFormat validates JsonConfig.ApiFormat; "clf" is accepted for "common".
+func parseApiFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		return "", nil
+	case "clf", apiFormatCommon:
+		return apiFormatCommon, nil
+	case apiFormatCombined:
+		return apiFormatCombined, nil
+	}
+	return "", fmt.Errorf("invalid apiFormat: %s", format)
+}
+
+// formatAccessLine renders an API record in the sink's ApiFormat, eg.
+//
+//	192.0.2.1 - - [16/Oct/2026:15:04:05 +0000] "GET /users/7 HTTP/1.1" 200 512 "-" "curl/8.5.0"
+//
+// Details only Middleware knows, such as the remote address, are "-" for records logged with API.
+func (c *LoggerConfig) formatAccessLine(api *apiRecord) string {
+	request := api.request
+	if request == nil {
+		request = &httpRequest{}
+	}
+	timestamp := request.start
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	if c.Utc {
+		timestamp = timestamp.UTC()
+	} else {
+		timestamp = timestamp.Local()
+	}
+
+	requestLine := "-"
+	if request.method != "" || api.requestPath != "" {
+		requestLine = clfField(request.method) + " " + clfField(api.requestPath) + " " + clfField(request.proto)
+	}
+	size := "-"
+	if request.bytes > 0 {
+		size = strconv.FormatInt(request.bytes, 10)
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] \"%s\" %d %s",
+		clfField(remoteHost(request.remoteAddr)),
+		clfField(request.user),
+		timestamp.Format(clfTimeLayout),
+		escapeCLF(requestLine),
+		api.statusCode,
+		size,
+	)
+	if c.ApiFormat == apiFormatCombined {
+		line += fmt.Sprintf(" \"%s\" \"%s\"", escapeCLF(clfField(request.referer)), escapeCLF(clfField(request.userAgent)))
+	}
+	return line
+}
+
+// writeAccessLine writes an API record to the sink's output in its ApiFormat. Access lines carry
+// their own timestamp, so they bypass the classic logger's prefix.
+func (c *LoggerConfig) writeAccessLine(api *apiRecord) error {
+	_, err := io.WriteString(c.output, c.formatAccessLine(api)+"\n")
+	return err
+}
+
+// accessLogHandler writes API records in the sink's ApiFormat instead of passing them to inner.
+type accessLogHandler struct {
+	inner  slog.Handler
+	config *LoggerConfig
+}
+
+func (h *accessLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *accessLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if api, ok := apiRecordFrom(ctx); ok {
+		return h.config.writeAccessLine(api)
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *accessLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &accessLogHandler{inner: h.inner.WithAttrs(attrs), config: h.config}
+}
+
+func (h *accessLogHandler) WithGroup(name string) slog.Handler {
+	return &accessLogHandler{inner: h.inner.WithGroup(name), config: h.config}
+}
+
+// remoteHost strips the port from a host:port remote address.
+func remoteHost(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// clfField returns value, or "-" when it is unknown.
+func clfField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// escapeCLF escapes quotes, backslashes and control characters the way Apache does in quoted fields.
+func escapeCLF(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,275 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatusLevels(t *testing.T) {
+	parsed, err := parseStatusLevels(map[string]string{
+		"4xx":     "error",
+		"404":     "info",
+		"420-429": "warning",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse status levels: %v", err)
+	}
+	config := &LoggerConfig{ApiStatusLevels: parsed}
+	expected := map[int]LogLevel{
+		404: INFO,    // exact code beats the class
+		429: WARNING, // range beats the class
+		418: ERROR,
+		304: INFO, // unmapped codes keep the default
+		305: WARNING,
+		503: ERROR,
+	}
+	for status, want := range expected {
+		if got := config.apiLevel(status); got != want {
+			t.Errorf("Expected status %d to map to %s, got %s", status, levelToString(want), levelToString(got))
+		}
+	}
+
+	for _, invalid := range []map[string]string{
+		{"6xx": "info"},
+		{"499-400": "info"},
+		{"abc": "info"},
+		{"404": "loud"},
+		{"404": "info|warning"},
+	} {
+		if _, err := parseStatusLevels(invalid); err == nil {
+			t.Errorf("Expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestModernLogger_ApiStatusLevels(t *testing.T) {
+	dir := t.TempDir()
+	classicPath := filepath.Join(dir, "classic.log")
+	jsonPath := filepath.Join(dir, "json.log")
+	statusLevels := map[string]string{"404": "info", "429": "warning"}
+
+	classic, err := NewLogger(JsonConfig{
+		Levels:          "INFO",
+		ApiLevels:       "INFO",
+		Output:          classicPath,
+		NoColors:        true,
+		ApiStatusLevels: statusLevels,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create classic logger: %v", err)
+	}
+	defer classic.Close()
+	classic.API(404, "health probe miss")
+	classic.API(429, "rate limited")
+
+	got, err := os.ReadFile(classicPath)
+	if err != nil {
+		t.Fatalf("Failed to read classic log: %v", err)
+	}
+	if !strings.Contains(string(got), "health probe miss") || strings.Contains(string(got), "rate limited") {
+		t.Errorf("Expected only the 404 at INFO with ApiLevels INFO, got: %s", got)
+	}
+
+	logger, err := NewLogger(JsonConfig{
+		Levels:          "INFO",
+		ApiLevels:       "INFO|WARNING",
+		Output:          jsonPath,
+		Json:            true,
+		ApiStatusLevels: statusLevels,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	defer logger.Close()
+	logger.API(404, "health probe miss")
+	logger.API(429, "rate limited")
+
+	got, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), got)
+	}
+	for i, want := range []string{"INFO", "WARN"} {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", i, err)
+		}
+		if entry["level"] != want {
+			t.Errorf("Expected line %d at %s, got: %s", i, want, lines[i])
+		}
+	}
+}
+
+func TestModernLogger_ApiFormat(t *testing.T) {
+	dir := t.TempDir()
+	combinedPath := filepath.Join(dir, "combined.log")
+	clfPath := filepath.Join(dir, "clf.log")
+
+	logger, err := NewLogger(JsonConfig{
+		Levels:    "INFO",
+		ApiLevels: "INFO|WARNING",
+		Output:    combinedPath,
+		NoColors:  true,
+		Utc:       true,
+		ApiFormat: "combined",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	handler := Middleware(logger, MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/users/7?expand=true", nil)
+	request.RemoteAddr = "192.0.2.1:54321"
+	request.Header.Set("Referer", "https://example.com/")
+	request.Header.Set("User-Agent", `curl/8.5.0 "quoted"`)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	logger.Info("application lines keep their format")
+
+	got, err := os.ReadFile(combinedPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), got)
+	}
+	prefix := `192.0.2.1 - - [`
+	suffix := `+0000] "GET /users/7?expand=true HTTP/1.1" 200 5 "https://example.com/" "curl/8.5.0 \"quoted\""`
+	if !strings.HasPrefix(lines[0], prefix) || !strings.HasSuffix(lines[0], suffix) {
+		t.Errorf("Expected a combined log format line, got: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " application lines keep their format") {
+		t.Errorf("Expected the classic format for application logs, got: %s", lines[1])
+	}
+
+	structured, err := NewLogger(JsonConfig{
+		Levels:    "INFO",
+		ApiLevels: "INFO|WARNING",
+		Output:    clfPath,
+		Json:      true,
+		ApiFormat: "clf",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	defer structured.Close()
+	structured.APIPath(404, "/missing", "not found")
+	structured.Info("json line")
+
+	got, err = os.ReadFile(clfPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), got)
+	}
+	if !strings.HasPrefix(lines[0], "- - - [") || !strings.HasSuffix(lines[0], `] "- /missing -" 404 -`) {
+		t.Errorf("Expected a common log format line, got: %s", lines[0])
+	}
+	if !json.Valid([]byte(lines[1])) {
+		t.Errorf("Expected JSON for application logs, got: %s", lines[1])
+	}
+
+	if _, err := NewLogger(JsonConfig{ApiFormat: "apache"}); err == nil {
+		t.Errorf("Expected an unknown apiFormat to be rejected")
+	}
+}
+
+func TestModernLogger_SlowRequests(t *testing.T) {
+	dir := t.TempDir()
+	classicPath := filepath.Join(dir, "classic.log")
+	jsonPath := filepath.Join(dir, "json.log")
+
+	// only escalated INFO records pass WARNING; the escalated 404 is an ERROR
+	classic, err := NewLogger(JsonConfig{
+		Levels:           "INFO",
+		ApiLevels:        "WARNING",
+		Output:           classicPath,
+		NoColors:         true,
+		ApiSlowThreshold: "500ms",
+		ApiSlowPaths:     map[string]string{"^/export": "10s", "^/export/small": "1s"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create classic logger: %v", err)
+	}
+	defer classic.Close()
+	classic.APIPath(200, "/users", "fast", "duration", 100*time.Millisecond)
+	classic.APIPath(200, "/users", "slow", "duration", 600*time.Millisecond)
+	classic.APIPath(200, "/export/all", "export within its threshold", "duration", 5*time.Second)
+	classic.APIPath(200, "/export/small", "export over the lowest matching threshold", "duration", 2*time.Second)
+	classic.APIPath(404, "/users/7", "slow miss", "duration", time.Second)
+
+	got, err := os.ReadFile(classicPath)
+	if err != nil {
+		t.Fatalf("Failed to read classic log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	expected := []string{" slow slow=true", " export over the lowest matching threshold slow=true"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %s", len(expected), len(lines), got)
+	}
+	for i, want := range expected {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("Expected line %d to end with '%s', got: %s", i, want, lines[i])
+		}
+	}
+
+	structured, err := NewLogger(JsonConfig{
+		Levels:           "INFO",
+		ApiLevels:        "INFO|WARNING",
+		Output:           jsonPath,
+		Json:             true,
+		ApiSlowThreshold: "500ms",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	defer structured.Close()
+	structured.API(200, "slow", "duration", time.Second)
+
+	got, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(got, &entry); err != nil {
+		t.Fatalf("Expected one JSON line, got: %s", got)
+	}
+	if entry["level"] != "WARN" || entry["slow"] != true || entry["duration"] == nil {
+		t.Errorf("Expected a slow WARN record with its duration, got: %s", got)
+	}
+
+	// format arguments are never taken for a duration
+	if got := apiDuration(nil, true, []any{"duration", time.Minute}); got != 0 {
+		t.Errorf("Expected no duration from formatted args, got %s", got)
+	}
+
+	// escalation stops at ERROR, without lowering more severe levels
+	alert, err := RegisterLevel("alert", slog.LevelError+2, RED)
+	if err != nil {
+		t.Fatalf("Failed to register level: %v", err)
+	}
+	for level, want := range map[LogLevel]LogLevel{INFO: WARNING, WARNING: ERROR, ERROR: ERROR, FATAL: FATAL, alert: alert} {
+		if got := escalateLevel(level); got != want {
+			t.Errorf("Expected %s to escalate to %s, got %s", levelToString(level), levelToString(want), levelToString(got))
+		}
+	}
+
+	if _, err := NewLogger(JsonConfig{ApiSlowThreshold: "fast"}); err == nil {
+		t.Errorf("Expected an invalid apiSlowThreshold to be rejected")
+	}
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Overflow policies accepted in JsonConfig.OverflowPolicy
+const (
+	overflowBlock      = "block"
+	overflowDropNewest = "drop_newest"
+	overflowDropOldest = "drop_oldest"
+)
+
+const (
+	defaultAsyncBufferSize = 1024
+	dropReportInterval     = 10 * time.Second
+)
+
+// asyncWriter queues each Write (one formatted log record) in a bounded ring buffer and writes it to
+// out on a background goroutine, so log calls never wait for the disk. When the buffer is full the
+// overflow policy decides whether the caller blocks or a record is dropped.
+type asyncWriter struct {
+	out    io.Writer
+	policy string
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond // signalled when a record is queued or the writer closes
+	notFull  *sync.Cond // broadcast when records are dequeued, the queue drains or the writer closes
+	ring     [][]byte
+	head     int
+	count    int
+	writing  bool   // the worker is writing a dequeued batch
+	dropped  uint64 // records dropped since the last report
+	closing  bool   // Close has been called
+	closed   bool   // no more records are accepted
+
+	report func(dropped uint64) // logs the dropped-record count through the owning sink
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newAsyncWriter wraps out with a queue of bufferSize records. Call start to begin writing.
+func newAsyncWriter(out io.Writer, bufferSize int, policy string) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	if policy == "" {
+		policy = overflowBlock
+	}
+	w := &asyncWriter{
+		out:    out,
+		policy: policy,
+		ring:   make([][]byte, bufferSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	w.notEmpty = sync.NewCond(&w.mu)
+	w.notFull = sync.NewCond(&w.mu)
+	return w
+}
+
+// start launches the writer goroutine and, when report is set, a goroutine reporting dropped
+// records every interval.
+func (w *asyncWriter) start(report func(dropped uint64), interval time.Duration) {
+	w.report = report
+	go w.run()
+	if report != nil {
+		go w.reportLoop(interval)
+	}
+}
+
+// Write queues a copy of p. It only blocks when the buffer is full and the policy is "block".
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	record := make([]byte, len(p))
+	copy(record, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.count == len(w.ring) && !w.closed {
+		switch w.policy {
+		case overflowDropNewest:
+			w.dropped++
+			return len(p), nil
+		case overflowDropOldest:
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dropped++
+		default:
+			w.notFull.Wait()
+		}
+	}
+	if w.closed {
+		// the owning logger was closed; late writes are dropped
+		return len(p), nil
+	}
+
+	w.ring[(w.head+w.count)%len(w.ring)] = record
+	w.count++
+	w.notEmpty.Signal()
+	return len(p), nil
+}
+
+// run writes queued records in batches until the writer is closed and the queue is empty.
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	batch := make([][]byte, 0, len(w.ring))
+	for {
+		w.mu.Lock()
+		for w.count == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+		if w.count == 0 {
+			w.mu.Unlock()
+			return
+		}
+		batch = batch[:0]
+		for w.count > 0 {
+			batch = append(batch, w.ring[w.head])
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+		}
+		w.writing = true
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+
+		for _, record := range batch {
+			if _, err := w.out.Write(record); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to log message '%s' with error `%v`\n", record, err)
+			}
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// reportLoop periodically logs how many records were dropped since the previous report.
+func (w *asyncWriter) reportLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.reportDropped()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// reportDropped resets the dropped counter and logs it if it was non-zero.
+func (w *asyncWriter) reportDropped() {
+	w.mu.Lock()
+	dropped := w.dropped
+	w.dropped = 0
+	w.mu.Unlock()
+
+	if dropped > 0 && w.report != nil {
+		w.report(dropped)
+	}
+}
+
+// Sync waits until every queued record has been written, then syncs out.
+func (w *asyncWriter) Sync() error {
+	w.mu.Lock()
+	for (w.count > 0 || w.writing) && !w.closed {
+		w.notFull.Wait()
+	}
+	w.mu.Unlock()
+
+	if syncer, ok := w.out.(interface{ Sync() error }); ok && w.out != os.Stdout {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Reopen reopens out if it is a file; queued records are written to the reopened file.
+func (w *asyncWriter) Reopen() error {
+	if reopener, ok := w.out.(interface{ Reopen() error }); ok {
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+// Close reports pending drops, writes everything still queued and closes out unless it is stdout.
+// Closing more than once is a no-op.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closing {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closing = true
+	w.mu.Unlock()
+
+	w.reportDropped()
+
+	w.mu.Lock()
+	w.closed = true
+	w.notEmpty.Broadcast()
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+
+	if closer, ok := w.out.(io.Closer); ok && w.out != os.Stdout {
+		return closer.Close()
+	}
+	return nil
+}
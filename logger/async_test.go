@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks every Write until release is closed.
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+func TestAsyncWriter_DropNewest(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	w := newAsyncWriter(out, 2, overflowDropNewest)
+	var reported uint64
+	w.start(func(dropped uint64) { reported += dropped }, time.Hour)
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// wait for the worker to pick up "first" and block on the gated writer
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		writing := w.writing
+		w.mu.Unlock()
+		if writing || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, line := range []string{"second\n", "third\n", "fourth\n", "fifth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	close(out.release)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := out.String(); got != "first\nsecond\nthird\n" {
+		t.Errorf("Expected the queued records to be written in order, got: %q", got)
+	}
+	if reported != 2 {
+		t.Errorf("Expected 2 dropped records to be reported, got %d", reported)
+	}
+}
+
+func TestAsyncWriter_DropOldest(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	w := newAsyncWriter(out, 2, overflowDropOldest)
+	// hold the queue until everything is written by not starting the worker yet
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	w.start(nil, time.Hour)
+	close(out.release)
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if got := out.String(); got != "second\nthird\n" {
+		t.Errorf("Expected the oldest record to be dropped, got: %q", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestModernLogger_Async(t *testing.T) {
+	logger, err := NewLogger(JsonConfig{
+		Levels:         "INFO",
+		NoColors:       true,
+		Async:          true,
+		BufferSize:     16,
+		OverflowPolicy: "drop_oldest",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	ml := logger.(*modernLogger)
+	async, ok := ml.outputs[0].(*asyncWriter)
+	if !ok {
+		t.Fatalf("Expected async output, got %T", ml.outputs[0])
+	}
+	var buf bytes.Buffer
+	async.out = &buf
+
+	logger.Info("queued message")
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "queued message") {
+		t.Errorf("Expected 'queued message' after Sync, got: %s", buf.String())
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := NewLogger(JsonConfig{Async: true, OverflowPolicy: "sometimes"}); err == nil {
+		t.Error("Expected error for invalid overflow policy, got nil")
+	}
+}
+
+func TestModernLogger_FatalFlushesAsync(t *testing.T) {
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", Async: true, Json: true, Output: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	async := logger.(*modernLogger).outputs[0].(*asyncWriter)
+	gated := &gatedWriter{release: make(chan struct{})}
+	async.out = gated
+
+	var exitCode int
+	var atExit string
+	exitProcess = func(code int) {
+		exitCode = code
+		atExit = gated.String()
+	}
+	defer func() { exitProcess = os.Exit }()
+
+	// the writer is still blocked when Fatal returns to exitAfterFatal, so the records are queued
+	time.AfterFunc(50*time.Millisecond, func() { close(gated.release) })
+	logger.Info("queued before fatal")
+	logger.Fatal("fatal record")
+
+	if exitCode != 1 {
+		t.Errorf("Expected exit status 1, got %d", exitCode)
+	}
+	if !strings.Contains(atExit, "queued before fatal") || !strings.Contains(atExit, "fatal record") {
+		t.Errorf("Expected queued records to be written before exiting, got: %s", atExit)
+	}
+}
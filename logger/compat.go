@@ -24,6 +24,15 @@ func GetGlobalLogger() Logger {
 }
 
 // Modern logging functions that use the global logger if available
+func TraceContext(ctx context.Context, msg string, args ...any) {
+	if globalLogger != nil {
+		globalLogger.TraceContext(ctx, msg, args...)
+	} else {
+		// Fall back to legacy logging
+		Trace(msg)
+	}
+}
+
 func DebugContext(ctx context.Context, msg string, args ...any) {
 	if globalLogger != nil {
 		globalLogger.DebugContext(ctx, msg, args...)
@@ -70,6 +79,15 @@ func FatalContext(ctx context.Context, msg string, args ...any) {
 }
 
 // Formatted context-aware functions
+func TracefContext(ctx context.Context, format string, args ...any) {
+	if globalLogger != nil {
+		globalLogger.TracefContext(ctx, format, args...)
+	} else {
+		// Fall back to legacy logging
+		Tracef(format, args...)
+	}
+}
+
 func DebugfContext(ctx context.Context, format string, args ...any) {
 	if globalLogger != nil {
 		globalLogger.DebugfContext(ctx, format, args...)
@@ -154,30 +172,42 @@ func APIfContext(ctx context.Context, statusCode int, format string, args ...any
 // noOpLogger is a no-op implementation for when no global logger is set
 type noOpLogger struct{}
 
+func (n *noOpLogger) Trace(msg string, args ...any)                                               {}
 func (n *noOpLogger) Debug(msg string, args ...any)                                               {}
 func (n *noOpLogger) Info(msg string, args ...any)                                                {}
 func (n *noOpLogger) Warn(msg string, args ...any)                                                {}
 func (n *noOpLogger) Error(msg string, args ...any)                                               {}
 func (n *noOpLogger) Fatal(msg string, args ...any)                                               {}
+func (n *noOpLogger) Tracef(format string, args ...any)                                           {}
 func (n *noOpLogger) Debugf(format string, args ...any)                                           {}
 func (n *noOpLogger) Infof(format string, args ...any)                                            {}
 func (n *noOpLogger) Warnf(format string, args ...any)                                            {}
 func (n *noOpLogger) Errorf(format string, args ...any)                                           {}
 func (n *noOpLogger) Fatalf(format string, args ...any)                                           {}
+func (n *noOpLogger) TraceContext(ctx context.Context, msg string, args ...any)                   {}
 func (n *noOpLogger) DebugContext(ctx context.Context, msg string, args ...any)                   {}
 func (n *noOpLogger) InfoContext(ctx context.Context, msg string, args ...any)                    {}
 func (n *noOpLogger) WarnContext(ctx context.Context, msg string, args ...any)                    {}
 func (n *noOpLogger) ErrorContext(ctx context.Context, msg string, args ...any)                   {}
 func (n *noOpLogger) FatalContext(ctx context.Context, msg string, args ...any)                   {}
+func (n *noOpLogger) TracefContext(ctx context.Context, format string, args ...any)               {}
 func (n *noOpLogger) DebugfContext(ctx context.Context, format string, args ...any)               {}
 func (n *noOpLogger) InfofContext(ctx context.Context, format string, args ...any)                {}
 func (n *noOpLogger) WarnfContext(ctx context.Context, format string, args ...any)                {}
 func (n *noOpLogger) ErrorfContext(ctx context.Context, format string, args ...any)               {}
 func (n *noOpLogger) FatalfContext(ctx context.Context, format string, args ...any)               {}
+func (n *noOpLogger) Log(ctx context.Context, level LogLevel, msg string, args ...any)            {}
 func (n *noOpLogger) With(args ...any) Logger                                                     { return n }
 func (n *noOpLogger) WithGroup(name string) Logger                                                { return n }
 func (n *noOpLogger) API(statusCode int, msg string, args ...any)                                 {}
-func (n *noOpLogger) APIPath(statusCode int, requestPath string, msg string, args ...any)        {}
+func (n *noOpLogger) APIPath(statusCode int, requestPath string, msg string, args ...any)         {}
 func (n *noOpLogger) APIf(statusCode int, format string, args ...any)                             {}
 func (n *noOpLogger) APIContext(ctx context.Context, statusCode int, msg string, args ...any)     {}
 func (n *noOpLogger) APIfContext(ctx context.Context, statusCode int, format string, args ...any) {}
+func (n *noOpLogger) APIPathContext(ctx context.Context, statusCode int, requestPath string, msg string, args ...any) {
+}
+func (n *noOpLogger) SetLevels(levels string) error    { return nil }
+func (n *noOpLogger) SetApiLevels(levels string) error { return nil }
+func (n *noOpLogger) Sync() error                      { return nil }
+func (n *noOpLogger) Reopen() error                    { return nil }
+func (n *noOpLogger) Close() error                     { return nil }
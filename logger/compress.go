@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const gzipExt = ".gz"
+
+// requestCompression wakes the compressor goroutine without ever blocking the caller; a pending
+// wake-up already covers any files rotated since it was sent. Caller must hold w.mu or own w
+// exclusively.
+func (w *rotatingFile) requestCompression() {
+	if w.compressSignal == nil {
+		return
+	}
+	select {
+	case w.compressSignal <- struct{}{}:
+	default:
+	}
+}
+
+// compressLoop gzips every uncompressed rotated file each time it is signalled. It runs on its own
+// goroutine so the logging path never waits for compression.
+func (w *rotatingFile) compressLoop(signal <-chan struct{}) {
+	defer close(w.compressDone)
+	for range signal {
+		files, err := w.rotatedFiles()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list rotated log files for '%v' with error `%v`\n", w.path, err)
+			continue
+		}
+		for _, name := range files {
+			if strings.HasSuffix(name, gzipExt) {
+				continue
+			}
+			if err := w.compressFile(name); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to compress log file '%v' with error `%v`\n", name, err)
+			}
+		}
+	}
+}
+
+// compressFile writes name.gz and removes name. Numbered backups can be shifted by a rotation while
+// they are being compressed, so the result is committed under w.mu against wherever the source file
+// lives by then.
+func (w *rotatingFile) compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = removeIfExists(tmpName) }() // no-op once renamed into place
+
+	gz := gzip.NewWriter(tmp)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := w.locate(name, srcInfo)
+	if current == "" {
+		// removed by retention while we were compressing
+		return nil
+	}
+	if err := os.Rename(tmpName, current+gzipExt); err != nil {
+		return err
+	}
+	return os.Remove(current)
+}
+
+// locate returns the path the file described by info currently has: name itself, or the numbered
+// backup it was shifted to. It returns "" when the file no longer exists. Caller must hold w.mu.
+func (w *rotatingFile) locate(name string, info os.FileInfo) string {
+	if sameFile(name, info) {
+		return name
+	}
+	if !strings.HasPrefix(name, w.path+".") {
+		return ""
+	}
+	for i := 1; fileExists(w.backupName(i)) || fileExists(w.backupName(i)+gzipExt); i++ {
+		if sameFile(w.backupName(i), info) {
+			return w.backupName(i)
+		}
+	}
+	return ""
+}
+
+func sameFile(name string, info os.FileInfo) bool {
+	current, err := os.Stat(name)
+	return err == nil && os.SameFile(current, info)
+}
@@ -1,22 +1,67 @@
 package logger
 
 import (
+	"io"
 	"log"
 	"regexp"
+	"slices"
+	"time"
 )
 
 // friendly config for yaml/json interfaces
 type JsonConfig struct {
 	Levels     string `json:"levels"`     // separated list of log levels to enable. (eg. "info|warning|error|debug")
 	ApiLevels  string `json:"apiLevels"`  // separated list of log levels to enable for the API. (eg. "info|warning|error")
-	Output     string `json:"output"`     // output location. (eg. "stdout" or "path/to/file.log")
+	Output     string `json:"output"`     // output location. (eg. "stdout", "path/to/file.log" or "gelf+udp://graylog:12201")
 	NoColors   bool   `json:"noColors"`   // disable colors in the output
 	Json       bool   `json:"json"`       // output in json format (enables structured logging)
 	Structured bool   `json:"structured"` // enable structured logging (default: false)
+	Format     string `json:"format"`     // structured output format other than json or text: "logfmt" or "ecs" (Elastic Common Schema JSON)
 	Utc        bool   `json:"utc"`        // use UTC time in the output instead of local time
 	// ApiPathExclude is a regex matched against the request path (and query if provided via ApiPath).
 	// When it matches, API access lines are not written to this logger output. Empty means no exclusion.
 	ApiPathExclude string `json:"apiPathExclude"`
+	// MaxSize is the size in megabytes a file output may reach before it is rotated to <output>.1,
+	// shifting older backups to <output>.2 and so on. Zero disables size-based rotation.
+	MaxSize int `json:"maxSize"`
+	// MaxBackups is the number of rotated files to keep. Zero keeps all of them.
+	MaxBackups int `json:"maxBackups"`
+	// Rotate renames a file output after the period it covers at each period boundary: "daily"
+	// (app.log -> app-2026-10-16.log) or "hourly" (app-2026-10-16T15.log). Utc selects which
+	// midnight or hour is used. Empty disables time-based rotation.
+	Rotate string `json:"rotate"`
+	// MaxAge is the number of days rotated files are kept before being deleted. Zero keeps them forever.
+	MaxAge int `json:"maxAge"`
+	// Compress gzips rotated files (app.log.1.gz, app-2026-10-16.log.gz) in the background.
+	// Files left uncompressed by a previous run are picked up at startup. For gelf+udp outputs it
+	// gzips each message instead.
+	Compress bool `json:"compress"`
+	// Async queues formatted records in a bounded buffer written by a background goroutine, so log
+	// calls don't wait for the output. BufferSize is the queue length in records (default 1024).
+	Async      bool `json:"async"`
+	BufferSize int  `json:"bufferSize"`
+	// OverflowPolicy decides what happens when the async buffer is full: "block" (default) waits for
+	// room, "drop_newest" discards the new record and "drop_oldest" discards the oldest queued one.
+	// Dropped records are counted and the count is logged periodically as a warning.
+	OverflowPolicy string `json:"overflowPolicy"`
+	// ApiStatusLevels overrides the level API records are logged at by status code, eg.
+	// {"404": "info", "429": "warning", "5xx": "error", "300-399": "debug"}. Codes not listed keep the
+	// default: 500 and up ERROR, 305-499 WARNING, everything else INFO.
+	ApiStatusLevels map[string]string `json:"apiStatusLevels"`
+	// ApiFormat renders API records (API, APIPath, Middleware) as access log lines that log shippers
+	// parse natively: "clf" (Common Log Format) or "combined" (adds referer and user agent). The
+	// message and attributes are not included. Empty keeps the normal format; other logs are unaffected.
+	ApiFormat string `json:"apiFormat"`
+	// ApiSlowThreshold raises the level of API records for requests slower than this duration (eg.
+	// "500ms") one step, INFO to WARNING or WARNING to ERROR, and marks them slow=true. ApiSlowPaths sets
+	// thresholds for request paths matching a regex, eg. {"^/export": "10s"}; when several match the
+	// lowest applies. Latency comes from Middleware or a time.Duration "duration" argument to API.
+	ApiSlowThreshold string            `json:"apiSlowThreshold"`
+	ApiSlowPaths     map[string]string `json:"apiSlowPaths"`
+	// ContextExtractors add attributes found in the context of *Context calls, such as request or
+	// user IDs stored by middleware, to every line of this output. They can only be set from code.
+	// A span context stored with ContextWithTraceparent is always added as trace_id, span_id and trace_flags.
+	ContextExtractors []ContextExtractor `json:"-"`
 }
 
 // go logger log config
@@ -32,11 +77,60 @@ type LoggerConfig struct {
 	FilePath     string
 	Structured   bool
 	Json         bool
+	Format       string // "logfmt", "ecs", "gelf" for GELF outputs or "" for the Json setting's format
 
 	// ApiPathExcludeRegex is compiled from JsonConfig.ApiPathExclude; when non-nil, API logs with a
 	// request path that matches are skipped for this sink only (see ApiPath / APIPath).
 	ApiPathExcludeRegex *regexp.Regexp
 
+	// MaxSize (megabytes) and MaxBackups control size-based rotation of FilePath, Rotate
+	// ("daily", "hourly") time-based rotation, MaxAge (days) retention and Compress gzipping of
+	// rotated files.
+	MaxSize    int
+	MaxBackups int
+	Rotate     string
+	MaxAge     int
+	Compress   bool
+
+	// Async, BufferSize and OverflowPolicy configure the asynchronous writer.
+	Async          bool
+	BufferSize     int
+	OverflowPolicy string
+
+	// ApiStatusLevels is parsed from JsonConfig.ApiStatusLevels, narrowest range first.
+	ApiStatusLevels []StatusLevel
+
+	// ApiFormat is "common", "combined" or "" to render API records like other logs.
+	ApiFormat string
+
+	// ApiSlowThreshold and ApiSlowPaths are parsed from the JsonConfig fields of the same name.
+	ApiSlowThreshold time.Duration
+	ApiSlowPaths     []SlowPath
+
+	// ContextExtractors add attributes taken from the context of *Context calls.
+	ContextExtractors []ContextExtractor
+
 	// not exposed
 	logger *log.Logger
+	output io.Writer // shared by logger and the sink's slog handler
+}
+
+// setLevels replaces the enabled levels. Caller must hold the owning modernLogger's write lock.
+func (c *LoggerConfig) setLevels(levels []LogLevel) {
+	c.Levels = levels
+	c.Disabled = slices.Contains(levels, DISABLED)
+	c.DebugEnabled = debugEnabled(levels)
+	if c.logger != nil {
+		var flags int
+		if c.DebugEnabled {
+			flags |= log.Lshortfile
+		}
+		c.logger.SetFlags(flags)
+	}
+}
+
+// setApiLevels replaces the enabled API levels. Caller must hold the owning modernLogger's write lock.
+func (c *LoggerConfig) setApiLevels(levels []LogLevel) {
+	c.ApiLevels = levels
+	c.DisabledAPI = slices.Contains(levels, DISABLED)
 }
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// loggerKey is the context key NewContext stores a Logger under.
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, so it does not have to be passed through every
+// layer by hand. Retrieve it with FromContext.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by NewContext. Without one it returns the global
+// logger, and without that a logger that discards everything, so the result is never nil.
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	if globalLogger != nil {
+		return globalLogger
+	}
+	return &noOpLogger{}
+}
+
+// contextAttrs returns the attributes bound (with With) to the logger stored in ctx that ml does
+// not already carry, eg. because ml was derived from that logger.
+func (ml *modernLogger) contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	ctxLogger, ok := ctx.Value(loggerKey{}).(*modernLogger)
+	if !ok || ctxLogger == ml {
+		return nil
+	}
+	var attrs []slog.Attr
+	for _, attr := range ctxLogger.attrs {
+		if !slices.ContainsFunc(ml.attrs, attr.Equal) {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// loggerAttrsKey is the context key of the attributes contextAttrs found for a record.
+type loggerAttrsKey struct{}
+
+func withLoggerAttrs(ctx context.Context, attrs []slog.Attr) context.Context {
+	return context.WithValue(ctx, loggerAttrsKey{}, attrs)
+}
+
+// ContextExtractor returns attributes to add to records logged with ctx, such as a request ID
+// stored in it by middleware. It is called for every *Context log call of the sinks it is
+// configured on (see JsonConfig.ContextExtractors), so it should be cheap.
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// ContextKey returns a ContextExtractor that adds the value stored in the context under key as
+// attribute name, when there is one.
+func ContextKey(key any, name string) ContextExtractor {
+	return func(ctx context.Context) []slog.Attr {
+		value := ctx.Value(key)
+		if value == nil {
+			return nil
+		}
+		return []slog.Attr{slog.Any(name, value)}
+	}
+}
+
+// extractContextAttrs collects the attributes of the logger stored in ctx (see contextAttrs), the span
+// context stored in it (see ContextWithTraceparent) and the attributes extractors find in it. Each key
+// is added once: a later attribute replaces an earlier one with the same key, so eg. a TraceExtractor's
+// span wins over the stored traceparent.
+func extractContextAttrs(ctx context.Context, extractors []ContextExtractor) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	add := func(found []slog.Attr) {
+		for _, attr := range found {
+			if i := slices.IndexFunc(attrs, func(a slog.Attr) bool { return a.Key == attr.Key }); i >= 0 {
+				attrs[i] = attr
+			} else {
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+	loggerAttrs, _ := ctx.Value(loggerAttrsKey{}).([]slog.Attr)
+	add(loggerAttrs)
+	add(spanAttrs(ctx))
+	for _, extract := range extractors {
+		add(extract(ctx))
+	}
+	return attrs
+}
+
+// contextAttrParts formats the context attributes of ctx as key=value parts of classic lines.
+func contextAttrParts(ctx context.Context, extractors []ContextExtractor) []string {
+	var parts []string
+	for _, attr := range extractContextAttrs(ctx, extractors) {
+		parts = appendAttr(parts, "", attr)
+	}
+	return parts
+}
+
+// contextAttrsHandler adds the attributes of a logger carried by the record's context, its span
+// context and the attributes a sink's ContextExtractors find in it. They describe the request rather
+// than the code that logged, so they stay at the top level even when the logger has groups open.
+type contextAttrsHandler struct {
+	base       slog.Handler                      // the sink handler, before WithAttrs and WithGroup
+	ops        []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls made since base
+	handler    slog.Handler                      // base with ops applied
+	grouped    bool                              // ops open a group
+	extractors []ContextExtractor
+}
+
+func newContextAttrsHandler(base slog.Handler, extractors []ContextExtractor) *contextAttrsHandler {
+	return &contextAttrsHandler{base: base, handler: base, extractors: extractors}
+}
+
+func (h *contextAttrsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *contextAttrsHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := extractContextAttrs(ctx, h.extractors)
+	if len(attrs) == 0 {
+		return h.handler.Handle(ctx, r)
+	}
+	if !h.grouped {
+		r.AddAttrs(attrs...)
+		return h.handler.Handle(ctx, r)
+	}
+	// record attributes would land in the open groups, so add these before replaying them
+	handler := h.base.WithAttrs(attrs)
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *contextAttrsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.derive(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) }, false)
+}
+
+func (h *contextAttrsHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.derive(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) }, true)
+}
+
+func (h *contextAttrsHandler) derive(op func(slog.Handler) slog.Handler, group bool) *contextAttrsHandler {
+	return &contextAttrsHandler{
+		base:       h.base,
+		ops:        append(slices.Clip(h.ops), op),
+		handler:    op(h.handler),
+		grouped:    h.grouped || group,
+		extractors: h.extractors,
+	}
+}
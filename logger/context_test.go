@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()).(*noOpLogger); !ok {
+		t.Errorf("Expected a no-op logger without a logger in the context or a global logger")
+	}
+
+	global, err := NewLogger(JsonConfig{Levels: "INFO"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	SetGlobalLogger(global)
+	t.Cleanup(func() { SetGlobalLogger(nil) })
+	if FromContext(context.Background()) != global {
+		t.Errorf("Expected the global logger without a logger in the context")
+	}
+
+	stored := global.With("request_id", "req-1")
+	if FromContext(NewContext(context.Background(), stored)) != stored {
+		t.Errorf("Expected the logger stored with NewContext")
+	}
+}
+
+func TestModernLogger_ContextLoggerAttrs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", Output: path, NoColors: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	requestLogger := logger.With("request_id", "req-1")
+	ctx := NewContext(context.Background(), requestLogger)
+
+	logger.InfoContext(ctx, "merged from context")
+	// a logger derived from the context logger already carries its attributes
+	requestLogger.With("user_id", 7).InfoContext(ctx, "not duplicated")
+	// they describe the request, so they stay out of the caller's groups
+	logger.WithGroup("db").InfoContext(ctx, "grouped", "table", "users")
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %s", len(lines), got)
+	}
+	if !strings.HasSuffix(lines[0], "merged from context request_id=req-1") {
+		t.Errorf("Expected context logger attributes on the line, got: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "not duplicated request_id=req-1 user_id=7") {
+		t.Errorf("Expected each attribute once, got: %s", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "grouped request_id=req-1") {
+		t.Errorf("Expected context logger attributes outside the group, got: %s", lines[2])
+	}
+
+	jsonPath := filepath.Join(t.TempDir(), "json.log")
+	jsonLogger, err := NewLogger(JsonConfig{Levels: "INFO", Output: jsonPath, Json: true})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	defer jsonLogger.Close()
+	jsonLogger.WithGroup("db").InfoContext(NewContext(context.Background(), jsonLogger.With("request_id", "req-1")), "grouped", "table", "users")
+
+	got, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(got, &entry); err != nil {
+		t.Fatalf("Expected one JSON line, got: %s", got)
+	}
+	if db, _ := entry["db"].(map[string]any); entry["request_id"] != "req-1" || len(db) != 1 || db["table"] != "users" {
+		t.Errorf("Expected request_id at the top level, got: %s", got)
+	}
+}
+
+func TestContextExtractors(t *testing.T) {
+	dir := t.TempDir()
+	classicPath := filepath.Join(dir, "classic.log")
+	jsonPath := filepath.Join(dir, "json.log")
+
+	extractors := []ContextExtractor{
+		ContextKey(testRequestIDKey, "request_id"),
+		func(ctx context.Context) []slog.Attr {
+			if user, ok := ctx.Value(testKey).(int); ok {
+				return []slog.Attr{slog.Int("user_id", user)}
+			}
+			return nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), testRequestIDKey, "req-1")
+	ctx = context.WithValue(ctx, testKey, 7)
+
+	classic, err := NewLogger(JsonConfig{Levels: "INFO", Output: classicPath, NoColors: true, ContextExtractors: extractors})
+	if err != nil {
+		t.Fatalf("Failed to create classic logger: %v", err)
+	}
+	defer classic.Close()
+	classic.InfoContext(ctx, "classic line")
+	classic.Info("without context")
+
+	got, err := os.ReadFile(classicPath)
+	if err != nil {
+		t.Fatalf("Failed to read classic log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), got)
+	}
+	if !strings.HasSuffix(lines[0], "classic line request_id=req-1 user_id=7") {
+		t.Errorf("Expected extracted attributes on the classic line, got: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "without context") {
+		t.Errorf("Expected no extracted attributes without a context, got: %s", lines[1])
+	}
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", Output: jsonPath, Json: true, ContextExtractors: extractors})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	defer logger.Close()
+	logger.WithGroup("db").InfoContext(ctx, "json line", "table", "users")
+
+	got, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(got, &entry); err != nil {
+		t.Fatalf("Expected one JSON line, got: %s", got)
+	}
+	db, _ := entry["db"].(map[string]any)
+	if entry["request_id"] != "req-1" || entry["user_id"] != float64(7) || db["table"] != "users" {
+		t.Errorf("Expected extracted attributes at the top level next to the db group, got: %s", got)
+	}
+}
@@ -5,21 +5,26 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 // customHandler implements slog.Handler with the original logger format
 type customHandler struct {
 	writer io.Writer
-	level  slog.Level
+	level  slog.Leveler
 	config *LoggerConfig
 	colors bool
 	utc    bool
+	attrs  []string // key=value pairs added with WithAttrs, already formatted
+	groups []string // groups opened with WithGroup, prefixed to the keys of later attributes
 }
 
 // NewCustomHandler creates a custom slog handler that mimics the original logger format
-func NewCustomHandler(writer io.Writer, level slog.Level, config *LoggerConfig) *customHandler {
+func NewCustomHandler(writer io.Writer, level slog.Leveler, config *LoggerConfig) *customHandler {
 	return &customHandler{
 		writer: writer,
 		level:  level,
@@ -31,7 +36,7 @@ func NewCustomHandler(writer io.Writer, level slog.Level, config *LoggerConfig)
 
 // Enabled returns true if the level is enabled
 func (h *customHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle processes a log record
@@ -48,7 +53,7 @@ func (h *customHandler) Handle(ctx context.Context, r slog.Record) error {
 	levelStr := h.formatLevel(r.Level)
 
 	// Format source location
-	source := h.formatSource(r.PC)
+	source := formatSource(r.PC)
 
 	// Format message
 	msg := r.Message
@@ -82,21 +87,43 @@ func (h *customHandler) Handle(ctx context.Context, r slog.Record) error {
 
 // WithAttrs returns a new handler with additional attributes
 func (h *customHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	// For simplicity, we'll just return the same handler
-	// In a more complex implementation, we'd store these attributes
-	return h
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := h.clone()
+	prefix := groupPrefix(h.groups)
+	for _, attr := range attrs {
+		clone.attrs = appendAttr(clone.attrs, prefix, attr)
+	}
+	return clone
 }
 
 // WithGroup returns a new handler with a group name
 func (h *customHandler) WithGroup(name string) slog.Handler {
-	// For simplicity, we'll just return the same handler
-	// In a more complex implementation, we'd store the group name
-	return h
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+// clone copies the handler so attributes and groups can be added without affecting h
+func (h *customHandler) clone() *customHandler {
+	clone := *h
+	clone.attrs = slices.Clip(h.attrs)
+	clone.groups = slices.Clip(h.groups)
+	return &clone
 }
 
 // formatLevel formats the log level to match the original format
 func (h *customHandler) formatLevel(level slog.Level) string {
+	if _, custom, ok := customLevelAt(level); ok {
+		return custom.name
+	}
 	switch {
+	case level >= slogLevelFatal:
+		return "FATAL"
 	case level >= slog.LevelError:
 		return "ERROR"
 	case level >= slog.LevelWarn:
@@ -106,67 +133,146 @@ func (h *customHandler) formatLevel(level slog.Level) string {
 	case level >= slog.LevelDebug:
 		return "DEBUG"
 	default:
-		return "INFO "
+		return "TRACE"
 	}
 }
 
-// formatSource formats the source location to match the original format
-func (h *customHandler) formatSource(pc uintptr) string {
+// formatSource formats the source location as file:line, see callerFrame.
+func formatSource(pc uintptr) string {
 	if pc == 0 {
 		return ""
 	}
+	frame := callerFrame(pc)
+	return fmt.Sprintf("%s:%d", stripProjectPath(frame.File), frame.Line)
+}
 
-	// Get the call stack and skip frames to reach the actual application code
-	// The call stack is: application code -> logger wrapper -> slog -> customHandler
-	callers := make([]uintptr, 10)
-	n := runtime.Callers(0, callers)
-
-	// Skip the first few frames to get to the application code
-	// Frame 0: formatSource
-	// Frame 1: Handle
-	// Frame 2: slog internal
-	// Frame 3: logger wrapper (Infof, Debugf, etc.)
-	// Frame 4: application code <- This is what we want
-	skipFrames := 5
-	if n > skipFrames {
-		frames := runtime.CallersFrames(callers[skipFrames:])
-		frame, _ := frames.Next()
-
-		// Extract just the filename and line number
-		file := frame.File
-		if idx := strings.LastIndex(file, "/"); idx != -1 {
-			file = file[idx+1:]
+// callerFrame returns the frame of the code that called the logger, for every handler. Records are
+// created by the logger's wrappers, so slog's pc points into this package; instead the call stack is
+// walked past the frames of log/slog and of this package's non-test files, however deep the handler
+// chain is. Records this package logs itself, such as Middleware's access lines, are attributed to
+// its own frame rather than to net/http or the runtime. Falls back to the frame of pc when no such
+// frame is found.
+func callerFrame(pc uintptr) runtime.Frame {
+	// application code -> logger wrapper -> slog -> handler chain -> callerFrame fits well within this
+	callers := make([]uintptr, 32)
+	n := runtime.Callers(2, callers)
+	frames := runtime.CallersFrames(callers[:n])
+	var previous runtime.Frame
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" && !isInternalFrame(frame) {
+			if previous.File != "" && !isEntryPoint(previous.Function) {
+				// the outermost internal frame isn't a Logger or slog method the caller used
+				return previous
+			}
+			return frame
+		}
+		previous = frame
+		if !more {
+			break
 		}
-
-		return fmt.Sprintf("%s:%d", file, frame.Line)
 	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame
+}
 
-	// Fallback to the original PC if we can't get enough frames
-	frames := runtime.CallersFrames([]uintptr{pc})
-	frame, _ := frames.Next()
-
-	// Extract just the filename and line number
-	file := frame.File
-	if idx := strings.LastIndex(file, "/"); idx != -1 {
-		file = file[idx+1:]
+// isInternalFrame reports whether frame belongs to log/slog or to this package, except its tests.
+func isInternalFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, "log/slog.") {
+		return true
 	}
+	return strings.HasPrefix(frame.Function, loggerPackage+".") && !strings.HasSuffix(frame.File, "_test.go")
+}
 
-	return fmt.Sprintf("%s:%d", file, frame.Line)
+// isEntryPoint reports whether function is an exported function or method, such as Logger.Info or
+// slog.Logger.Info, rather than an unexported helper or a closure like Middleware.func1.
+func isEntryPoint(function string) bool {
+	name := function[strings.LastIndex(function, ".")+1:]
+	return name != "" && unicode.IsUpper(rune(name[0]))
 }
 
-// formatAttrs formats the attributes as key-value pairs
+// loggerPackage is the import path of this package, the prefix of its function names.
+var loggerPackage = reflect.TypeOf(customHandler{}).PkgPath()
+
+// formatAttrs formats the handler's and the record's attributes as key-value pairs
 func (h *customHandler) formatAttrs(r slog.Record) string {
-	if r.NumAttrs() == 0 {
+	if r.NumAttrs() == 0 && len(h.attrs) == 0 {
 		return ""
 	}
 
-	var parts []string
+	parts := slices.Clone(h.attrs)
+	prefix := groupPrefix(h.groups)
 	r.Attrs(func(attr slog.Attr) bool {
-		parts = append(parts, fmt.Sprintf("%s=%v", attr.Key, attr.Value.Any()))
+		parts = appendAttr(parts, prefix, attr)
 		return true
 	})
 
-	return strings.Join(parts, " ")
+	return joinAttrs(parts)
+}
+
+// groupPrefix turns open groups into a key prefix, eg. ["api", "request"] -> "api.request."
+func groupPrefix(groups []string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return strings.Join(groups, ".") + "."
+}
+
+// appendAttr formats attr as key=value, flattening group values into dotted keys the way the JSON
+// handler nests them.
+func appendAttr(parts []string, prefix string, attr slog.Attr) []string {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return parts
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			parts = appendAttr(parts, prefix, member)
+		}
+		return parts
+	}
+	value := fmt.Sprint(attr.Value.Any())
+	if strings.Contains(value, "\n") {
+		// multi-line values such as stack traces are written as an indented block
+		return append(parts, prefix+attr.Key+"=\n"+indentBlock(value))
+	}
+	return append(parts, prefix+attr.Key+"="+value)
+}
+
+// joinAttrs joins key=value parts with spaces, moving indented blocks to the end of the line so
+// they don't split it.
+func joinAttrs(parts []string) string {
+	var line, blocks []string
+	for _, part := range parts {
+		if strings.Contains(part, "\n") {
+			blocks = append(blocks, part)
+		} else {
+			line = append(line, part)
+		}
+	}
+	joined := strings.Join(line, " ")
+	for i, block := range blocks {
+		switch {
+		case i > 0:
+			joined += "\n"
+		case joined != "":
+			joined += " "
+		}
+		joined += block
+	}
+	return joined
+}
+
+// indentBlock indents every line of value by four spaces.
+func indentBlock(value string) string {
+	lines := strings.Split(strings.TrimRight(value, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
 }
 
 // getLevelColor returns the color code for a log level
@@ -174,6 +280,9 @@ func (h *customHandler) getLevelColor(level slog.Level) string {
 	if !h.colors {
 		return ""
 	}
+	if _, custom, ok := customLevelAt(level); ok {
+		return custom.color
+	}
 
 	switch {
 	case level >= slog.LevelError:
@@ -182,9 +291,7 @@ func (h *customHandler) getLevelColor(level slog.Level) string {
 		return YELLOW
 	case level >= slog.LevelInfo:
 		return ""
-	case level >= slog.LevelDebug:
-		return GRAY
 	default:
-		return ""
+		return GRAY
 	}
 }
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// ecsVersion is the Elastic Common Schema version ECS output conforms to.
+const ecsVersion = "8.11.0"
+
+// newECSHandler returns a JSON handler writing Elastic Common Schema field names: @timestamp,
+// log.level, message and log.origin.* instead of time, level, msg and source, and trace.id and
+// span.id for the span context. API records get url.path, url.query and http.response.status_code;
+// the latter is added by ecsAPIExtractor.
+func newECSHandler(writer io.Writer, level slog.Leveler, utc bool) slog.Handler {
+	handler := slog.NewJSONHandler(writer, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.TimeKey:
+				a.Key = "@timestamp"
+				if utc {
+					a.Value = slog.TimeValue(a.Value.Time().UTC())
+				}
+			case slog.LevelKey:
+				a = slog.String("log.level", strings.ToLower(slogLevelLabel(a.Value.Any().(slog.Level))))
+			case slog.MessageKey:
+				a.Key = "message"
+			case slog.SourceKey:
+				source := a.Value.Any().(*slog.Source)
+				file, line, function := source.File, source.Line, source.Function
+				if frame := callerFrame(0); frame.File != "" {
+					file, line, function = frame.File, frame.Line, frame.Function
+				}
+				// an empty key inlines the group, so the dotted keys stay at the top level
+				a = slog.Attr{Value: slog.GroupValue(
+					slog.String("log.origin.file.name", stripProjectPath(file)),
+					slog.Int("log.origin.file.line", line),
+					slog.String("log.origin.function", stripFunctionPath(function)),
+				)}
+			case "request_path":
+				// request_path may carry the query, which ECS keeps in url.query
+				path, query, ok := strings.Cut(a.Value.String(), "?")
+				a = slog.String("url.path", path)
+				if ok {
+					a = slog.Attr{Value: slog.GroupValue(a, slog.String("url.query", query))}
+				}
+			case "trace_id":
+				a.Key = "trace.id"
+			case "span_id":
+				a.Key = "span.id"
+			}
+			return a
+		},
+	})
+	return handler.WithAttrs([]slog.Attr{slog.String("ecs.version", ecsVersion)})
+}
+
+// ecsAPIExtractor adds the status code of API records as http.response.status_code.
+func ecsAPIExtractor(ctx context.Context) []slog.Attr {
+	api, ok := apiRecordFrom(ctx)
+	if !ok {
+		return nil
+	}
+	return []slog.Attr{slog.Int("http.response.status_code", api.statusCode)}
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModernLogger_ECS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ecs.log")
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", ApiLevels: "INFO|WARNING", Output: path, Format: "ecs", Utc: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx, err := ContextWithTraceparent(context.Background(), testTraceparent)
+	if err != nil {
+		t.Fatalf("Failed to store traceparent: %v", err)
+	}
+	logger.WithGroup("db").InfoContext(ctx, "user created", "table", "users")
+	logger.APIPathContext(ctx, 404, "/users/7", "not found")
+	logger.APIPath(200, "/search?q=logs&page=2", "found")
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %s", len(lines), got)
+	}
+
+	var entry, access map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	timestamp, _ := entry["@timestamp"].(string)
+	db, _ := entry["db"].(map[string]any)
+	expected := map[string]any{
+		"log.level":            "info",
+		"message":              "user created",
+		"log.origin.file.name": "ecs_test.go",
+		"trace.id":             "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span.id":              "00f067aa0ba902b7",
+		"ecs.version":          ecsVersion,
+	}
+	for key, want := range expected {
+		if entry[key] != want {
+			t.Errorf("Expected %s=%v, got %v in: %s", key, want, entry[key], lines[0])
+		}
+	}
+	if !strings.HasSuffix(timestamp, "Z") || entry["log.origin.file.line"] == nil || db["table"] != "users" {
+		t.Errorf("Expected UTC @timestamp, source line and grouped attributes, got: %s", lines[0])
+	}
+	for _, key := range []string{"time", "level", "msg", "source", "trace_id"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("Expected %s to be renamed, got: %s", key, lines[0])
+		}
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &access); err != nil {
+		t.Fatalf("Failed to parse API line: %v", err)
+	}
+	if access["log.level"] != "warn" || access["url.path"] != "/users/7" || access["http.response.status_code"] != float64(404) {
+		t.Errorf("Expected the API record's status code and path in ECS fields, got: %s", lines[1])
+	}
+	if _, ok := access["url.query"]; ok {
+		t.Errorf("Expected no url.query without a query, got: %s", lines[1])
+	}
+
+	var search map[string]any
+	if err := json.Unmarshal([]byte(lines[2]), &search); err != nil {
+		t.Fatalf("Failed to parse API line: %v", err)
+	}
+	if search["url.path"] != "/search" || search["url.query"] != "q=logs&page=2" {
+		t.Errorf("Expected the query in url.query, got: %s", lines[2])
+	}
+}
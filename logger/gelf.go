@@ -0,0 +1,389 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outputs starting with these schemes send GELF messages to Graylog instead of writing to a file
+const (
+	gelfUDPScheme = "gelf+udp"
+	gelfTCPScheme = "gelf+tcp"
+)
+
+const (
+	// gelfChunkSize is the size of UDP datagrams, including the chunk header, that fits the MTU of
+	// most networks.
+	gelfChunkSize = 1420
+	// gelfChunkHeaderSize is the size of the magic bytes, message ID, sequence number and count.
+	gelfChunkHeaderSize = 12
+	// gelfMaxChunks is the most chunks Graylog reassembles into one message.
+	gelfMaxChunks = 128
+	// gelfDialTimeout bounds connecting to a TCP input, and each write to it.
+	gelfDialTimeout = 5 * time.Second
+	// gelfMinBackoff and gelfMaxBackoff bound how long records are dropped after a TCP input couldn't
+	// be reached, before connecting is tried again. The wait doubles with each failure.
+	gelfMinBackoff = time.Second
+	gelfMaxBackoff = time.Minute
+)
+
+// errGELFUnavailable is returned for records dropped while waiting to reconnect to a TCP input.
+var errGELFUnavailable = errors.New("gelf output is unavailable")
+
+// formatGELF is the Format of sinks with a GELF output; it can't be set in JsonConfig.Format.
+const formatGELF = "gelf"
+
+// gelfFieldName matches the additional field names Graylog accepts.
+var gelfFieldName = regexp.MustCompile(`^[\w.\-]+$`)
+
+// isGELFOutput reports whether output is a gelf+udp:// or gelf+tcp:// address.
+func isGELFOutput(output string) bool {
+	scheme, _, ok := strings.Cut(output, "://")
+	scheme = strings.ToLower(scheme)
+	return ok && (scheme == gelfUDPScheme || scheme == gelfTCPScheme)
+}
+
+// parseGELFOutput validates a GELF output and returns its network ("udp" or "tcp") and address.
+// compress is only supported over UDP, as Graylog's TCP input doesn't accept compressed messages.
+func parseGELFOutput(output string, compress bool) (string, string, error) {
+	parsed, err := url.Parse(output)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid gelf output: %w", err)
+	}
+	if parsed.Port() == "" || parsed.Hostname() == "" || (parsed.Path != "" && parsed.Path != "/") {
+		return "", "", fmt.Errorf("invalid gelf output: %s, expected %s://host:port", output, parsed.Scheme)
+	}
+	network := strings.TrimPrefix(strings.ToLower(parsed.Scheme), "gelf+")
+	if network == "tcp" && compress {
+		return "", "", fmt.Errorf("invalid gelf output: compress is not supported over tcp")
+	}
+	return network, parsed.Host, nil
+}
+
+// gelfWriter sends each Write, one GELF message, to a Graylog input: as one or more chunked, optionally
+// gzipped datagrams over UDP, or null-byte terminated over TCP.
+type gelfWriter struct {
+	mu       sync.Mutex
+	network  string
+	address  string
+	compress bool
+	conn     net.Conn // nil until a TCP connection is (re)established
+	closed   bool
+	backoff  time.Duration // current wait between TCP connection attempts, zero when connected
+	retryAt  time.Time     // when the next TCP connection attempt may be made
+	dropped  int           // records dropped since the TCP input became unreachable
+}
+
+func newGELFWriter(network, address string, compress bool) (*gelfWriter, error) {
+	w := &gelfWriter{network: network, address: address, compress: compress}
+	if network == "udp" {
+		// dialing UDP only resolves the address, so a Graylog that is down doesn't fail startup
+		conn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gelf output: %w", err)
+		}
+		w.conn = conn
+	}
+	return w, nil
+}
+
+func (w *gelfWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, fmt.Errorf("gelf output %s is closed", w.address)
+	}
+	message := bytes.TrimSuffix(p, []byte("\n"))
+	var err error
+	if w.network == "udp" {
+		err = w.writeUDP(message)
+	} else {
+		err = w.writeTCP(message)
+	}
+	if err != nil {
+		if !errors.Is(err, errGELFUnavailable) {
+			fmt.Fprintf(os.Stderr, "failed to send log message to gelf output '%v' with error `%v`\n", w.address, err)
+		}
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeUDP sends message in one datagram, or in chunks when it doesn't fit.
+func (w *gelfWriter) writeUDP(message []byte) error {
+	if w.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(message); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		message = buf.Bytes()
+	}
+	if len(message) <= gelfChunkSize {
+		_, err := w.conn.Write(message)
+		return err
+	}
+
+	payloadSize := gelfChunkSize - gelfChunkHeaderSize
+	count := (len(message) + payloadSize - 1) / payloadSize
+	if count > gelfMaxChunks {
+		return fmt.Errorf("gelf message of %d bytes exceeds %d chunks", len(message), gelfMaxChunks)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, gelfChunkSize)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*payloadSize, len(message))
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, message[i*payloadSize:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTCP sends message followed by a null byte, connecting first if needed. A failed write, or one
+// that doesn't finish within gelfDialTimeout, is retried once on a new connection, as Graylog may have
+// closed an idle one. When that fails too, records are dropped without waiting for the input until
+// the backoff has passed, so an outage doesn't stall every log call.
+func (w *gelfWriter) writeTCP(message []byte) error {
+	if w.conn == nil && time.Now().Before(w.retryAt) {
+		w.dropped++
+		return errGELFUnavailable
+	}
+	frame := append(message[:len(message):len(message)], 0)
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			conn, err := net.DialTimeout(w.network, w.address, gelfDialTimeout)
+			if err != nil {
+				w.backOff()
+				return fmt.Errorf("failed to connect, dropping records for %s: %w", w.backoff, err)
+			}
+			w.conn = conn
+		}
+		err := w.conn.SetWriteDeadline(time.Now().Add(gelfDialTimeout))
+		if err == nil {
+			_, err = w.conn.Write(frame)
+		}
+		if err == nil {
+			if w.dropped > 0 {
+				fmt.Fprintf(os.Stderr, "dropped %d log messages while gelf output '%v' was unavailable\n", w.dropped, w.address)
+			}
+			w.backoff, w.dropped = 0, 0
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+		if attempt > 0 {
+			w.backOff()
+			return fmt.Errorf("%w, dropping records for %s", err, w.backoff)
+		}
+	}
+}
+
+// backOff doubles the wait before the next TCP connection attempt, within gelfMinBackoff and
+// gelfMaxBackoff.
+func (w *gelfWriter) backOff() {
+	w.backoff = min(max(2*w.backoff, gelfMinBackoff), gelfMaxBackoff)
+	w.retryAt = time.Now().Add(w.backoff)
+}
+
+func (w *gelfWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// gelfField is an additional field added with WithAttrs, with its key already prefixed.
+type gelfField struct {
+	key   string
+	value any
+}
+
+// gelfHandler is a slog.Handler encoding records as GELF 1.1 messages: the level is mapped to a
+// syslog severity and attributes become "_"-prefixed additional fields, with dotted keys for groups.
+type gelfHandler struct {
+	writer io.Writer
+	level  slog.Leveler
+	host   string
+	fields []gelfField
+	groups []string
+}
+
+func newGELFHandler(writer io.Writer, level slog.Leveler) *gelfHandler {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return &gelfHandler{writer: writer, level: level, host: host}
+}
+
+func (h *gelfHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *gelfHandler) Handle(ctx context.Context, r slog.Record) error {
+	message := map[string]any{
+		"version":       "1.1",
+		"host":          h.host,
+		"short_message": r.Message,
+		"level":         syslogSeverity(r.Level),
+		"_level_name":   slogLevelLabel(r.Level),
+	}
+	if short, _, multiline := strings.Cut(r.Message, "\n"); multiline {
+		message["short_message"] = short
+		message["full_message"] = r.Message
+	}
+	if !r.Time.IsZero() {
+		message["timestamp"] = float64(r.Time.UnixMilli()) / 1e3
+	}
+	if r.PC != 0 {
+		frame := callerFrame(r.PC)
+		message["_file"] = stripProjectPath(frame.File)
+		message["_line"] = frame.Line
+	}
+	for _, field := range h.fields {
+		message[field.key] = field.value
+	}
+	prefix := groupPrefix(h.groups)
+	r.Attrs(func(attr slog.Attr) bool {
+		appendGELFField(message, prefix, attr)
+		return true
+	})
+
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(encoded)
+	return err
+}
+
+func (h *gelfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := h.clone()
+	fields := map[string]any{}
+	prefix := groupPrefix(h.groups)
+	for _, attr := range attrs {
+		appendGELFField(fields, prefix, attr)
+	}
+	for key, value := range fields {
+		clone.fields = append(clone.fields, gelfField{key: key, value: value})
+	}
+	return clone
+}
+
+func (h *gelfHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+// clone copies the handler so attributes and groups can be added without affecting h
+func (h *gelfHandler) clone() *gelfHandler {
+	clone := *h
+	clone.fields = slices.Clip(h.fields)
+	clone.groups = slices.Clip(h.groups)
+	return &clone
+}
+
+// appendGELFField adds attr to message as an additional field, flattening group values into dotted
+// keys. GELF only allows string and number values, so everything else is sent as a string.
+func appendGELFField(message map[string]any, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			appendGELFField(message, prefix, member)
+		}
+		return
+	}
+
+	key := prefix + attr.Key
+	if !gelfFieldName.MatchString(key) {
+		key = strings.Map(func(r rune) rune {
+			if r == '.' || r == '-' || r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+				return r
+			}
+			return '_'
+		}, key)
+	}
+	if key == "id" {
+		// _id is reserved by Graylog
+		key = "id_"
+	}
+
+	var value any
+	switch attr.Value.Kind() {
+	case slog.KindInt64:
+		value = attr.Value.Int64()
+	case slog.KindUint64:
+		value = attr.Value.Uint64()
+	case slog.KindFloat64:
+		value = attr.Value.Float64()
+	default:
+		value = logfmtValueString(attr.Value)
+	}
+	message["_"+key] = value
+}
+
+// syslogSeverity maps a level to the syslog severity GELF uses: 2 (critical) for FATAL, 3 (error),
+// 4 (warning), 5 (notice) for custom levels between INFO and WARNING, 6 (informational) and 7 (debug)
+// for DEBUG and TRACE.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slogLevelFatal:
+		return 2
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level > slog.LevelInfo:
+		return 5
+	case level >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readGELFUDP reads one GELF message from conn, reassembling chunks and decompressing gzip.
+func readGELFUDP(t *testing.T, conn net.PacketConn) map[string]any {
+	t.Helper()
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("Failed to set deadline: %v", err)
+	}
+	buf := make([]byte, 65536)
+	var message []byte
+	chunks := map[byte][]byte{}
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Failed to read datagram: %v", err)
+		}
+		datagram := buf[:n]
+		if n < 2 || datagram[0] != 0x1e || datagram[1] != 0x0f {
+			message = append([]byte(nil), datagram...)
+			break
+		}
+		if n > gelfChunkSize {
+			t.Fatalf("Chunk of %d bytes exceeds %d", n, gelfChunkSize)
+		}
+		chunks[datagram[10]] = append([]byte(nil), datagram[gelfChunkHeaderSize:]...)
+		if count := int(datagram[11]); len(chunks) == count {
+			for i := 0; i < count; i++ {
+				message = append(message, chunks[byte(i)]...)
+			}
+			break
+		}
+	}
+	if bytes.HasPrefix(message, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(message))
+		if err != nil {
+			t.Fatalf("Failed to open gzip message: %v", err)
+		}
+		if message, err = io.ReadAll(zr); err != nil {
+			t.Fatalf("Failed to decompress message: %v", err)
+		}
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(message, &decoded); err != nil {
+		t.Fatalf("Failed to parse GELF message: %v: %s", err, message)
+	}
+	return decoded
+}
+
+func TestModernLogger_GELFUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO|WARNING", Output: "gelf+udp://" + conn.LocalAddr().String(), Compress: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.With("id", 7).WithGroup("req").Warn("slow query\nSELECT 1", "duration_ms", 250, "ok", true)
+	message := readGELFUDP(t, conn)
+	expected := map[string]any{
+		"version":          "1.1",
+		"short_message":    "slow query",
+		"full_message":     "slow query\nSELECT 1",
+		"level":            float64(4),
+		"_id_":             float64(7),
+		"_req.duration_ms": float64(250),
+		"_req.ok":          "true",
+		"_file":            "gelf_test.go",
+	}
+	for key, want := range expected {
+		if message[key] != want {
+			t.Errorf("Expected %s=%v, got %v in: %v", key, want, message[key], message)
+		}
+	}
+	if message["host"] == "" || message["timestamp"] == nil {
+		t.Errorf("Expected host and timestamp, got: %v", message)
+	}
+
+	// random data doesn't compress, so this message needs several chunks
+	random := make([]byte, 4096)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatalf("Failed to generate data: %v", err)
+	}
+	logger.Info("large", "data", hex.EncodeToString(random))
+	if message := readGELFUDP(t, conn); message["_data"] != hex.EncodeToString(random) || message["level"] != float64(6) {
+		t.Errorf("Expected the chunked message to be reassembled, got short_message=%v", message["short_message"])
+	}
+}
+
+func TestModernLogger_GELFTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			message, err := reader.ReadString(0)
+			if err != nil {
+				break
+			}
+			messages = append(messages, strings.TrimSuffix(message, "\x00"))
+		}
+		received <- messages
+	}()
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO|ERROR", Output: "gelf+tcp://" + listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	logger.Info("first")
+	logger.Error("second", "user", "jane doe")
+
+	var messages []string
+	select {
+	case messages = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for messages")
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 null-terminated messages, got: %q", messages)
+	}
+	var second map[string]any
+	if err := json.Unmarshal([]byte(messages[1]), &second); err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	if second["short_message"] != "second" || second["level"] != float64(3) || second["_user"] != "jane doe" {
+		t.Errorf("Unexpected GELF message: %s", messages[1])
+	}
+}
+
+func TestGELFWriter_TCPBackoff(t *testing.T) {
+	// a closed listener leaves an address that refuses connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	w, err := newGELFWriter("tcp", address, false)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+	if _, err := w.Write([]byte(`{"short_message":"first"}`)); err == nil || errors.Is(err, errGELFUnavailable) {
+		t.Fatalf("Expected the first write to fail connecting, got: %v", err)
+	}
+	if w.backoff != gelfMinBackoff {
+		t.Errorf("Expected a backoff of %s, got %s", gelfMinBackoff, w.backoff)
+	}
+
+	// until the backoff has passed records are dropped without connecting
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte(`{"short_message":"dropped"}`)); !errors.Is(err, errGELFUnavailable) {
+			t.Fatalf("Expected the record to be dropped, got: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || w.dropped != 3 {
+		t.Errorf("Expected 3 records to be dropped at once, got %d in %s", w.dropped, elapsed)
+	}
+
+	// once the input is back, the next attempt after the backoff reconnects
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	w.address = listener.Addr().String()
+	w.retryAt = time.Time{}
+	if _, err := w.Write([]byte(`{"short_message":"back"}`)); err != nil {
+		t.Fatalf("Expected the write to reconnect, got: %v", err)
+	}
+	if w.backoff != 0 || w.dropped != 0 {
+		t.Errorf("Expected the backoff to be reset, got %s with %d dropped", w.backoff, w.dropped)
+	}
+}
+
+func TestGELFConfig(t *testing.T) {
+	invalid := []JsonConfig{
+		{Output: "gelf+udp://localhost"},                         // missing port
+		{Output: "gelf+udp://:12201"},                            // missing host
+		{Output: "gelf+tcp://localhost:12201", Compress: true},   // tcp can't be compressed
+		{Output: "gelf+udp://localhost:12201", Format: "logfmt"}, // gelf is the format
+		{Output: "gelf+udp://localhost:12201", ApiFormat: "clf"},
+		{Output: "gelf+udp://localhost:12201", MaxSize: 10},
+		{Output: "gelf+tcp://localhost:12201", Rotate: "daily"},
+		{Output: "gelf+tcp://localhost:12201", MaxAge: 7},
+	}
+	for _, config := range invalid {
+		if _, err := NewLogger(config); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+
+	severities := map[LogLevel]int{FATAL: 2, ERROR: 3, WARNING: 4, INFO: 6, DEBUG: 7, TRACE: 7}
+	for level, want := range severities {
+		if got := syslogSeverity(toSlogLevel(level)); got != want {
+			t.Errorf("Expected %s to map to severity %d, got %d", levelToString(level), want, got)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 // Logger interface for dependency injection and modern Go practices
 type Logger interface {
 	// Basic logging methods
+	Trace(msg string, args ...any)
 	Debug(msg string, args ...any)
 	Info(msg string, args ...any)
 	Warn(msg string, args ...any)
@@ -14,6 +15,7 @@ type Logger interface {
 	Fatal(msg string, args ...any)
 
 	// Formatted logging methods
+	Tracef(format string, args ...any)
 	Debugf(format string, args ...any)
 	Infof(format string, args ...any)
 	Warnf(format string, args ...any)
@@ -21,6 +23,7 @@ type Logger interface {
 	Fatalf(format string, args ...any)
 
 	// Context-aware methods
+	TraceContext(ctx context.Context, msg string, args ...any)
 	DebugContext(ctx context.Context, msg string, args ...any)
 	InfoContext(ctx context.Context, msg string, args ...any)
 	WarnContext(ctx context.Context, msg string, args ...any)
@@ -28,12 +31,16 @@ type Logger interface {
 	FatalContext(ctx context.Context, msg string, args ...any)
 
 	// Formatted context-aware methods
+	TracefContext(ctx context.Context, format string, args ...any)
 	DebugfContext(ctx context.Context, format string, args ...any)
 	InfofContext(ctx context.Context, format string, args ...any)
 	WarnfContext(ctx context.Context, format string, args ...any)
 	ErrorfContext(ctx context.Context, format string, args ...any)
 	FatalfContext(ctx context.Context, format string, args ...any)
 
+	// Logging at any level, including levels added with RegisterLevel
+	Log(ctx context.Context, level LogLevel, msg string, args ...any)
+
 	// Structured logging
 	With(args ...any) Logger
 	WithGroup(name string) Logger
@@ -43,7 +50,17 @@ type Logger interface {
 	APIPath(statusCode int, requestPath string, msg string, args ...any)
 	APIf(statusCode int, format string, args ...any)
 	APIContext(ctx context.Context, statusCode int, msg string, args ...any)
+	APIPathContext(ctx context.Context, statusCode int, requestPath string, msg string, args ...any)
 	APIfContext(ctx context.Context, statusCode int, format string, args ...any)
+
+	// Runtime configuration
+	SetLevels(levels string) error    // eg. "info|warning|error|debug"
+	SetApiLevels(levels string) error // eg. "info|warning|error"
+
+	// Lifecycle
+	Sync() error   // flush file outputs to disk
+	Reopen() error // reopen file outputs, e.g. after an external logrotate
+	Close() error  // close every owned output; later log calls are no-ops
 }
 
 // slog.Logger interface compatibility
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// customLevel is a level added with RegisterLevel.
+type customLevel struct {
+	name     string
+	severity slog.Level
+	color    string
+}
+
+var (
+	customLevelsMu    sync.RWMutex
+	customLevels      = map[LogLevel]customLevel{}
+	nextCustomLevel   = LogLevel(100)
+	builtinSeverities = []slog.Level{slogLevelTrace, slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, slogLevelFatal}
+)
+
+// RegisterLevel adds a custom log level such as NOTICE or AUDIT and returns its LogLevel. Once
+// registered, name can be used in JsonConfig.Levels and records logged with Logger.Log at that level
+// are rendered with name and color (eg. logger.GREEN, or "" for none).
+//
+// severity orders the level among the built-in ones on the slog scale: TRACE -8, DEBUG -4, INFO 0,
+// WARN 4, ERROR 8, FATAL 12. It must differ from the built-in severities and from other custom levels.
+// Registering the same name with the same severity again returns the existing level.
+//
+//	notice, err := logger.RegisterLevel("NOTICE", 2, logger.GREEN)
+//	log.Log(ctx, notice, "configuration reloaded")
+func RegisterLevel(name string, severity slog.Level, color string) (LogLevel, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" || len(SplitByMultiple(name)) != 1 {
+		return 0, fmt.Errorf("invalid level name: %q", name)
+	}
+	if _, ok := stringToLevel[name]; ok || name == "INFO" || name == "WARN" || name == "WARNING" {
+		return 0, fmt.Errorf("level %s is built in", name)
+	}
+	for _, builtin := range builtinSeverities {
+		if severity == builtin {
+			return 0, fmt.Errorf("severity %d is used by a built-in level", severity)
+		}
+	}
+
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+
+	for level, existing := range customLevels {
+		if existing.name == name && existing.severity == severity {
+			existing.color = color
+			customLevels[level] = existing
+			return level, nil
+		}
+		if existing.name == name {
+			return 0, fmt.Errorf("level %s is already registered with severity %d", name, existing.severity)
+		}
+		if existing.severity == severity {
+			return 0, fmt.Errorf("severity %d is already used by level %s", severity, existing.name)
+		}
+	}
+
+	level := nextCustomLevel
+	nextCustomLevel++
+	customLevels[level] = customLevel{name: name, severity: severity, color: color}
+	return level, nil
+}
+
+// lookupCustomLevel returns the custom level registered under name.
+func lookupCustomLevel(name string) (LogLevel, bool) {
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	for level, custom := range customLevels {
+		if custom.name == name {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// customLevelOf returns the definition of a custom level.
+func customLevelOf(level LogLevel) (customLevel, bool) {
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	custom, ok := customLevels[level]
+	return custom, ok
+}
+
+// customLevelAt returns the custom level registered with exactly severity.
+func customLevelAt(severity slog.Level) (LogLevel, customLevel, bool) {
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	for level, custom := range customLevels {
+		if custom.severity == severity {
+			return level, custom, true
+		}
+	}
+	return 0, customLevel{}, false
+}
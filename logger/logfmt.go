@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Output formats accepted in JsonConfig.Format
+const (
+	formatLogfmt = "logfmt"
+	formatECS    = "ecs"
+)
+
+// logfmtTimeLayout is the timestamp layout of logfmt output.
+const logfmtTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// parseFormat validates JsonConfig.Format.
+func parseFormat(format string, json bool) (string, error) {
+	switch format = strings.ToLower(format); format {
+	case "":
+		return "", nil
+	case formatLogfmt:
+		if json {
+			return "", fmt.Errorf("invalid format: %s can't be combined with json", format)
+		}
+		return format, nil
+	case formatECS:
+		// a JSON format, so json may be set too
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid format: %s", format)
+}
+
+// logfmtHandler is a slog.Handler writing one logfmt line per record, eg.
+//
+//	time=2026-10-16T15:04:05.000Z level=INFO source=main.go:42 msg="user created" user.id=7
+//
+// Attributes in groups get dotted keys.
+type logfmtHandler struct {
+	writer io.Writer
+	level  slog.Leveler
+	utc    bool
+	attrs  []byte   // " key=value" pairs added with WithAttrs, already encoded
+	groups []string // groups opened with WithGroup, prefixed to the keys of later attributes
+}
+
+func newLogfmtHandler(writer io.Writer, level slog.Leveler, utc bool) *logfmtHandler {
+	return &logfmtHandler{writer: writer, level: level, utc: utc}
+}
+
+func (h *logfmtHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *logfmtHandler) Handle(ctx context.Context, r slog.Record) error {
+	var line []byte
+	if !r.Time.IsZero() {
+		timestamp := r.Time.Local()
+		if h.utc {
+			timestamp = r.Time.UTC()
+		}
+		line = append(line, "time="...)
+		line = timestamp.AppendFormat(line, logfmtTimeLayout)
+		line = append(line, ' ')
+	}
+	line = append(line, "level="...)
+	line = appendLogfmtValue(line, slogLevelLabel(r.Level))
+	if source := formatSource(r.PC); source != "" {
+		line = append(line, " source="...)
+		line = appendLogfmtValue(line, source)
+	}
+	line = append(line, " msg="...)
+	line = appendLogfmtValue(line, r.Message)
+	line = append(line, h.attrs...)
+
+	prefix := groupPrefix(h.groups)
+	r.Attrs(func(attr slog.Attr) bool {
+		line = appendLogfmtAttr(line, prefix, attr)
+		return true
+	})
+	line = append(line, '\n')
+
+	_, err := h.writer.Write(line)
+	return err
+}
+
+func (h *logfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := h.clone()
+	prefix := groupPrefix(h.groups)
+	for _, attr := range attrs {
+		clone.attrs = appendLogfmtAttr(clone.attrs, prefix, attr)
+	}
+	return clone
+}
+
+func (h *logfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+// clone copies the handler so attributes and groups can be added without affecting h
+func (h *logfmtHandler) clone() *logfmtHandler {
+	clone := *h
+	clone.attrs = slices.Clip(h.attrs)
+	clone.groups = slices.Clip(h.groups)
+	return &clone
+}
+
+// appendLogfmtAttr appends attr as " key=value", flattening group values into dotted keys.
+func appendLogfmtAttr(line []byte, prefix string, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return line
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			line = appendLogfmtAttr(line, prefix, member)
+		}
+		return line
+	}
+	line = append(line, ' ')
+	line = appendLogfmtKey(line, prefix+attr.Key)
+	line = append(line, '=')
+	return appendLogfmtValue(line, logfmtValueString(attr.Value))
+}
+
+// logfmtValueString renders a resolved attribute value.
+func logfmtValueString(value slog.Value) string {
+	switch value.Kind() {
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return value.String()
+}
+
+// appendLogfmtKey appends key with the characters logfmt doesn't allow in keys replaced by '_'.
+func appendLogfmtKey(line []byte, key string) []byte {
+	if key == "" {
+		return append(line, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f {
+			r = '_'
+		}
+		line = utf8.AppendRune(line, r)
+	}
+	return line
+}
+
+// appendLogfmtValue appends value, quoted and escaped when it is empty or contains spaces, '=',
+// quotes, backslashes or control characters.
+func appendLogfmtValue(line []byte, value string) []byte {
+	if !needsLogfmtQuoting(value) {
+		return append(line, value...)
+	}
+	line = append(line, '"')
+	for _, r := range value {
+		switch r {
+		case '"', '\\':
+			line = append(line, '\\', byte(r))
+		case '\n':
+			line = append(line, `\n`...)
+		case '\r':
+			line = append(line, `\r`...)
+		case '\t':
+			line = append(line, `\t`...)
+		default:
+			if r < ' ' || r == 0x7f {
+				line = fmt.Appendf(line, `\u%04x`, r)
+			} else {
+				line = utf8.AppendRune(line, r)
+			}
+		}
+	}
+	return append(line, '"')
+}
+
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
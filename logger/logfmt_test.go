@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestModernLogger_Logfmt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := NewLogger(JsonConfig{Levels: "INFO|WARNING", ApiLevels: "INFO|WARNING", Output: path, Format: "logfmt", Utc: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.With("user_id", 7).WithGroup("req").Info("user created", "note", `said "hi"`+"\nbye", "empty", "", "err", errors.New("a=b"))
+	logger.APIPath(404, "/users/7", "not found")
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), got)
+	}
+	first := regexp.MustCompile(`^time=\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}Z level=INFO source=logfmt_test\.go:\d+ msg="user created" user_id=7 req\.note="said \\"hi\\"\\nbye" req\.empty="" req\.err="a=b"$`)
+	if !first.MatchString(lines[0]) {
+		t.Errorf("Unexpected logfmt line: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `level=WARN source=logfmt_test.go:`) || !strings.HasSuffix(lines[1], `msg="not found" request_path=/users/7`) {
+		t.Errorf("Unexpected logfmt API line: %s", lines[1])
+	}
+
+	if _, err := NewLogger(JsonConfig{Format: "logfmt", Json: true}); err == nil {
+		t.Errorf("Expected logfmt combined with json to be rejected")
+	}
+	if _, err := NewLogger(JsonConfig{Format: "xml"}); err == nil {
+		t.Errorf("Expected an unknown format to be rejected")
+	}
+}
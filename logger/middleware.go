@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// MiddlewareOptions configures Middleware.
+type MiddlewareOptions struct {
+	// RequestAttrs returns attributes bound to the request-scoped logger, eg. a request ID. They are
+	// added to the access line and to every line logged through FromContext(r.Context()).
+	RequestAttrs func(r *http.Request) []any
+}
+
+// Middleware logs one API line per request through logger.APIPathContext, with the method, status,
+// bytes written and duration. The request path and query are passed as request_path, so each
+// output's ApiPathExclude applies. The handler's request context carries a request-scoped logger
+// (see FromContext) and the span context of a valid traceparent header (see ContextWithTraceparent).
+func Middleware(logger Logger, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ctx := r.Context()
+			if traceparent := r.Header.Get("traceparent"); traceparent != "" {
+				if traced, err := ContextWithTraceparent(ctx, traceparent); err == nil {
+					ctx = traced
+				}
+			}
+			requestLogger := logger
+			if opts.RequestAttrs != nil {
+				if attrs := opts.RequestAttrs(r); len(attrs) > 0 {
+					requestLogger = logger.With(attrs...)
+				}
+			}
+			ctx = NewContext(ctx, requestLogger)
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			duration := time.Since(start)
+			requestPath := r.URL.Path
+			if r.URL.RawQuery != "" {
+				requestPath += "?" + r.URL.RawQuery
+			}
+			user, _, _ := r.BasicAuth()
+			ctx = withHTTPRequest(ctx, &httpRequest{
+				remoteAddr: r.RemoteAddr,
+				user:       user,
+				method:     r.Method,
+				proto:      r.Proto,
+				referer:    r.Referer(),
+				userAgent:  r.UserAgent(),
+				bytes:      recorder.bytes,
+				start:      start,
+				duration:   duration,
+			})
+			requestLogger.APIPathContext(ctx, recorder.status, requestPath,
+				fmt.Sprintf("%s %s %d %dB %s", r.Method, requestPath, recorder.status, recorder.bytes, duration),
+				"method", r.Method,
+				"status", recorder.status,
+				"bytes", recorder.bytes,
+				"duration", duration,
+			)
+		})
+	}
+}
+
+// RecoveryMiddleware recovers panics in the handlers it wraps, logs them at ERROR with the panic
+// value, request method and path and the goroutine stack, and responds with 500 Internal Server
+// Error if nothing was written yet. The stack is an attribute in structured output and an indented
+// block in text output. Wrap it in Middleware so the access line records the 500.
+//
+// http.ErrAbortHandler is re-panicked, so the server still aborts the response as intended.
+func RecoveryMiddleware(logger Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				// bound rather than passed, so classic text output includes them too
+				logger.With(
+					"panic", fmt.Sprint(recovered),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()),
+				).ErrorContext(r.Context(), "panic recovered")
+				if !recorder.wroteHeader {
+					http.Error(recorder, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// responseRecorder captures the status code and body size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		// informational responses are followed by the real one
+		r.wroteHeader = status >= http.StatusOK || status == http.StatusSwitchingProtocols
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush passes through to the underlying writer so streaming handlers keep working.
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Hijack passes through to the underlying writer so websocket upgrades keep working.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T does not support hijacking", r.ResponseWriter)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && !r.wroteHeader {
+		r.status = http.StatusSwitchingProtocols
+		r.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
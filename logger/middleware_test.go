@@ -0,0 +1,203 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	logger, err := NewLogger(JsonConfig{
+		Levels:         "INFO",
+		ApiLevels:      "INFO|WARNING|ERROR",
+		Output:         path,
+		Json:           true,
+		ApiPathExclude: "^/health",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	handler := Middleware(logger, MiddlewareOptions{
+		RequestAttrs: func(r *http.Request) []any {
+			return []any{"request_id", r.Header.Get("X-Request-Id")}
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			return
+		}
+		FromContext(r.Context()).InfoContext(r.Context(), "looking up user")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+		w.(http.Flusher).Flush()
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/users/7?expand=true", nil)
+	request.Header.Set("X-Request-Id", "req-1")
+	request.Header.Set("traceparent", testTraceparent)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if !response.Flushed {
+		t.Errorf("Expected Flush to reach the underlying writer")
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected the handler line and one access line, got %d: %s", len(lines), got)
+	}
+
+	var handlerLine, access map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &handlerLine); err != nil {
+		t.Fatalf("Failed to parse handler line: %v", err)
+	}
+	if handlerLine["request_id"] != "req-1" || handlerLine["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected request-scoped logger with request_id and trace_id, got: %s", lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &access); err != nil {
+		t.Fatalf("Failed to parse access line: %v", err)
+	}
+	expected := map[string]any{
+		"level":        "WARN",
+		"request_path": "/users/7?expand=true",
+		"method":       "GET",
+		"status":       float64(404),
+		"bytes":        float64(9),
+		"request_id":   "req-1",
+		"trace_id":     "4bf92f3577b34da6a3ce929d0e0e4736",
+	}
+	for key, want := range expected {
+		if access[key] != want {
+			t.Errorf("Expected access line %s=%v, got %v", key, want, access[key])
+		}
+	}
+	if _, ok := access["duration"]; !ok {
+		t.Errorf("Expected a duration on the access line, got: %s", lines[1])
+	}
+}
+
+func TestResponseRecorder_Hijack(t *testing.T) {
+	// httptest.ResponseRecorder can't be hijacked, which must be reported rather than panic
+	recorder := &responseRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	if _, _, err := recorder.Hijack(); err == nil {
+		t.Errorf("Expected an error hijacking a writer without Hijacker")
+	}
+
+	server := httptest.NewServer(Middleware(&noOpLogger{}, MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 204 No Content\r\n\r\n"))
+		_ = conn.Close()
+	})))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected the hijacked connection's response, got %d", response.StatusCode)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	for _, config := range []JsonConfig{
+		{Output: filepath.Join(dir, "classic.log")},
+		{Output: filepath.Join(dir, "structured.log"), Structured: true},
+	} {
+		config.Levels = "INFO|ERROR"
+		config.NoColors = true
+		logger, err := NewLogger(config)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		response := httptest.NewRecorder()
+		RecoveryMiddleware(logger)(panicking).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/explode", nil))
+		_ = logger.Close()
+
+		if response.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", response.Code)
+		}
+		got, err := os.ReadFile(config.Output)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		first, block, _ := strings.Cut(string(got), "\n")
+		if !strings.Contains(first, "panic recovered") || !strings.Contains(first, "panic=boom method=GET path=/explode stack=") {
+			t.Errorf("Expected panic details on the first line of %s, got: %s", config.Output, first)
+		}
+		if !strings.HasPrefix(block, "    goroutine ") || !strings.Contains(block, "\n    runtime/debug.Stack()") {
+			t.Errorf("Expected an indented stack block in %s, got: %s", config.Output, block)
+		}
+	}
+
+	path := filepath.Join(dir, "json.log")
+	logger, err := NewLogger(JsonConfig{Levels: "INFO|ERROR", ApiLevels: "INFO|WARNING|ERROR", Output: path, Json: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	handler := Middleware(logger, MiddlewareOptions{})(RecoveryMiddleware(logger)(panicking))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/explode", nil))
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected the panic and the access line, got %d: %s", len(lines), got)
+	}
+	var panicLine, access map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &panicLine); err != nil {
+		t.Fatalf("Failed to parse panic line: %v", err)
+	}
+	stack, _ := panicLine["stack"].(string)
+	if panicLine["level"] != "ERROR" || panicLine["panic"] != "boom" || panicLine["method"] != "POST" || !strings.Contains(stack, "goroutine ") {
+		t.Errorf("Expected an ERROR record with the panic, method and stack, got: %s", lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &access); err != nil {
+		t.Fatalf("Failed to parse access line: %v", err)
+	}
+	if access["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("Expected the access line to record the 500, got: %s", lines[1])
+	}
+
+	// records the middleware logs itself are attributed to it, not to net/http or the runtime
+	for i, entry := range []map[string]any{panicLine, access} {
+		if source, _ := entry["source"].(map[string]any); source["file"] != "middleware.go" {
+			t.Errorf("Expected the source to be middleware.go, got: %s", lines[i])
+		}
+	}
+}
+
+func TestRecoveryMiddleware_ErrAbortHandler(t *testing.T) {
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to be re-panicked, got: %v", recovered)
+		}
+	}()
+	RecoveryMiddleware(&noOpLogger{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -2,49 +2,130 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"regexp"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // modernLogger implements the Logger interface with modern Go practices
 type modernLogger struct {
+	*loggerCore
+	slog *slog.Logger
+
+	// attrs are the attributes bound with With, each nested in the groups open when it was bound.
+	// The slog handlers keep their own copy; the classic path appends these to every line.
+	attrs  []slog.Attr
+	groups []string
+}
+
+// loggerCore holds the sinks of a logger. Children created with With and WithGroup share it with
+// their parent, so they write to the same outputs and follow the same level changes, Close and addConfig.
+type loggerCore struct {
 	mu       sync.RWMutex
 	configs  []*LoggerConfig
-	slog     *slog.Logger
 	handlers []slog.Handler
 	outputs  []io.Writer
+	closed   bool
+
+	// generation changes whenever a sink is added, telling sinksHandlers to rebuild
+	generation atomic.Uint64
 }
 
 // NewLogger creates a new Logger instance with modern features
 func NewLogger(config JsonConfig) (Logger, error) {
+	loggerInstance, slogHandler, output, err := newSink(config)
+	if err != nil {
+		return nil, err
+	}
+
+	core := &loggerCore{
+		configs:  []*LoggerConfig{loggerInstance},
+		handlers: []slog.Handler{slogHandler},
+		outputs:  []io.Writer{output},
+	}
+	// Fan slog records out to every sink, including ones added later
+	ml := &modernLogger{
+		loggerCore: core,
+		slog:       slog.New(&sinksHandler{core: core}),
+	}
+
+	return ml, nil
+}
+
+// addConfig adds a new logger configuration to an existing modernLogger
+func (ml *modernLogger) addConfig(config JsonConfig) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if ml.closed {
+		return fmt.Errorf("logger is closed")
+	}
+
+	loggerInstance, slogHandler, output, err := newSink(config)
+	if err != nil {
+		return err
+	}
+
+	// Add to arrays
+	ml.configs = append(ml.configs, loggerInstance)
+	ml.handlers = append(ml.handlers, slogHandler)
+	ml.outputs = append(ml.outputs, output)
+
+	// sinksHandlers of this logger and its children pick up the new handler on their next record
+	ml.generation.Add(1)
+
+	return nil
+}
+
+// newSink builds everything one output needs: the classic logger config, the slog handler and the
+// writer both of them share.
+func newSink(config JsonConfig) (*LoggerConfig, slog.Handler, io.Writer, error) {
 	// Convert JsonConfig to LoggerConfig
 	loggerConfig, err := convertJsonConfigToLoggerConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("convert config: %w", err)
+		return nil, nil, nil, fmt.Errorf("convert config: %w", err)
 	}
 
-	// Create slog logger for structured logging
-	var slogHandler slog.Handler
-	var output io.Writer = os.Stdout
+	output, err := openOutput(loggerConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var async *asyncWriter
+	if loggerConfig.Async {
+		async = newAsyncWriter(output, loggerConfig.BufferSize, loggerConfig.OverflowPolicy)
+		output = async
+	}
+	loggerConfig.output = output
 
-	if config.Output != "" && strings.ToUpper(config.Output) != "STDOUT" {
-		file, err := os.OpenFile(config.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open log file: %w", err)
-		}
-		output = file
+	// Create the logger instance with proper initialization. AddLogger returns a copy, which the
+	// handlers below must share so SetLevels reaches both paths.
+	loggerConfig, err = AddLogger(*loggerConfig)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("create logger instance: %w", err)
 	}
 
-	slogLevel := convertLogLevelsToSlogLevel(config.Levels)
+	// levelSetHandler decides which records reach the sink, so the base handler accepts every level
+	slogLevel := slogLevelAll
 
-	if config.Json {
+	// Create handler for this config
+	var slogHandler slog.Handler
+	extractors := loggerConfig.ContextExtractors
+	switch {
+	case loggerConfig.Format == formatGELF:
+		slogHandler = newGELFHandler(output, slogLevel)
+	case loggerConfig.Format == formatECS:
+		slogHandler = newECSHandler(output, slogLevel, loggerConfig.Utc)
+		extractors = append([]ContextExtractor{ecsAPIExtractor}, extractors...)
+	case config.Json:
 		// Use JSON handler for JSON output
 		slogHandler = slog.NewJSONHandler(output, &slog.HandlerOptions{
 			AddSource: true,
@@ -52,97 +133,201 @@ func NewLogger(config JsonConfig) (Logger, error) {
 			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 				if a.Key == slog.SourceKey {
 					source := a.Value.Any().(*slog.Source)
+					if frame := callerFrame(0); frame.File != "" {
+						source.File, source.Line, source.Function = frame.File, frame.Line, frame.Function
+					}
 					source.File = stripProjectPath(source.File)
 					source.Function = stripFunctionPath(source.Function)
 				}
+				if a.Key == slog.LevelKey && len(groups) == 0 {
+					a.Value = slog.StringValue(slogLevelLabel(a.Value.Any().(slog.Level)))
+				}
 				return a
 			},
 		})
-	} else {
+	case loggerConfig.Format == formatLogfmt:
+		slogHandler = newLogfmtHandler(output, slogLevel, loggerConfig.Utc)
+	default:
 		// Use custom handler for text output to maintain original format
 		slogHandler = NewCustomHandler(output, slogLevel, loggerConfig)
 	}
+	if loggerConfig.ApiFormat != "" {
+		slogHandler = &accessLogHandler{inner: slogHandler, config: loggerConfig}
+	}
+	slogHandler = &levelSetHandler{inner: slogHandler, config: loggerConfig}
 	if loggerConfig.ApiPathExcludeRegex != nil {
 		slogHandler = &apiPathFilterHandler{inner: slogHandler, exclude: loggerConfig.ApiPathExcludeRegex}
 	}
+	slogHandler = newContextAttrsHandler(slogHandler, extractors)
+
+	if async != nil {
+		// dropped records are reported through this sink's own handler, in its own format
+		async.start(func(dropped uint64) {
+			record := slog.NewRecord(time.Now(), slog.LevelWarn, "dropped log records", 0)
+			record.AddAttrs(slog.Uint64("dropped", dropped))
+			_ = slogHandler.Handle(context.Background(), record)
+		}, dropReportInterval)
+	}
+	return loggerConfig, slogHandler, output, nil
+}
 
-	// Create the logger instance with proper initialization
-	loggerInstance, err := AddLogger(*loggerConfig)
+// openOutput returns the writer for a sink: stdout, a gelfWriter for GELF outputs or a rotatingFile
+// for file outputs.
+func openOutput(config *LoggerConfig) (io.Writer, error) {
+	if config.Stdout {
+		return os.Stdout, nil
+	}
+	if config.Format == formatGELF {
+		network, address, err := parseGELFOutput(config.FilePath, config.Compress)
+		if err != nil {
+			return nil, err
+		}
+		return newGELFWriter(network, address, config.Compress)
+	}
+	file, err := newRotatingFile(config.FilePath, rotateOptions{
+		maxSize:    int64(config.MaxSize) * megabyte,
+		maxBackups: config.MaxBackups,
+		every:      config.Rotate,
+		utc:        config.Utc,
+		maxAge:     time.Duration(config.MaxAge) * 24 * time.Hour,
+		compress:   config.Compress,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create logger instance: %w", err)
+		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
+	return file, nil
+}
 
-	ml := &modernLogger{
-		configs:  []*LoggerConfig{loggerInstance},
-		handlers: []slog.Handler{slogHandler},
-		outputs:  []io.Writer{output},
+// SetLevels replaces the enabled log levels (eg. "info|warning|error") of every sink, for both the
+// classic and the structured output. It is safe to call while other goroutines are logging.
+func (ml *modernLogger) SetLevels(levels string) error {
+	parsed, err := parseLevels(levels, false)
+	if err != nil {
+		return err
 	}
-	// Create a multi-handler for slog
-	ml.slog = slog.New(newMultiHandler(ml.handlers))
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
 
-	return ml, nil
+	for _, config := range ml.configs {
+		config.setLevels(parsed)
+	}
+	return nil
 }
 
-// addConfig adds a new logger configuration to an existing modernLogger
-func (ml *modernLogger) addConfig(config JsonConfig) error {
+// SetApiLevels replaces the enabled API log levels of every sink.
+func (ml *modernLogger) SetApiLevels(levels string) error {
+	parsed, err := parseLevels(levels, true)
+	if err != nil {
+		return err
+	}
+
 	ml.mu.Lock()
 	defer ml.mu.Unlock()
 
-	// Convert JsonConfig to LoggerConfig
-	loggerConfig, err := convertJsonConfigToLoggerConfig(config)
-	if err != nil {
-		return fmt.Errorf("convert config: %w", err)
+	for _, config := range ml.configs {
+		config.setApiLevels(parsed)
 	}
+	return nil
+}
 
-	// Create output writer
-	var output io.Writer = os.Stdout
-	if config.Output != "" && strings.ToUpper(config.Output) != "STDOUT" {
-		file, err := os.OpenFile(config.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
-			return fmt.Errorf("failed to open log file: %w", err)
+// setSinkLevels changes the levels and/or API levels of the sink at index, or of every sink when index
+// is negative. Empty strings leave the corresponding list unchanged.
+func (ml *modernLogger) setSinkLevels(index int, levels, apiLevels string) error {
+	var parsed, parsedApi []LogLevel
+	var err error
+	if levels != "" {
+		if parsed, err = parseLevels(levels, false); err != nil {
+			return err
+		}
+	}
+	if apiLevels != "" {
+		if parsedApi, err = parseLevels(apiLevels, true); err != nil {
+			return err
 		}
-		output = file
 	}
 
-	slogLevel := convertLogLevelsToSlogLevel(config.Levels)
+	return ml.restoreSinkLevels(index, parsed, parsedApi)
+}
 
-	// Create handler for this config
-	var slogHandler slog.Handler
-	if config.Json {
-		slogHandler = slog.NewJSONHandler(output, &slog.HandlerOptions{
-			AddSource: true,
-			Level:     slogLevel,
-			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-				if a.Key == slog.SourceKey {
-					source := a.Value.Any().(*slog.Source)
-					source.File = stripProjectPath(source.File)
-					source.Function = stripFunctionPath(source.Function)
-				}
-				return a
-			},
-		})
-	} else {
-		slogHandler = NewCustomHandler(output, slogLevel, loggerConfig)
+// restoreSinkLevels sets already parsed levels and/or API levels of the sink at index, or of every sink
+// when index is negative. Nil slices leave the corresponding list unchanged.
+func (ml *modernLogger) restoreSinkLevels(index int, levels, apiLevels []LogLevel) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if index >= len(ml.configs) {
+		return fmt.Errorf("sink %d does not exist", index)
 	}
-	if loggerConfig.ApiPathExcludeRegex != nil {
-		slogHandler = &apiPathFilterHandler{inner: slogHandler, exclude: loggerConfig.ApiPathExcludeRegex}
+	for i, config := range ml.configs {
+		if index >= 0 && i != index {
+			continue
+		}
+		if levels != nil {
+			config.setLevels(levels)
+		}
+		if apiLevels != nil {
+			config.setApiLevels(apiLevels)
+		}
 	}
+	return nil
+}
 
-	// Create the logger instance
-	loggerInstance, err := AddLogger(*loggerConfig)
-	if err != nil {
-		return fmt.Errorf("create logger instance: %w", err)
+// Sync flushes every file output to disk.
+func (ml *modernLogger) Sync() error {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	var errs []error
+	for _, output := range ml.outputs {
+		if syncer, ok := output.(interface{ Sync() error }); ok && output != os.Stdout {
+			if err := syncer.Sync(); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	// Add to arrays
-	ml.configs = append(ml.configs, loggerInstance)
-	ml.handlers = append(ml.handlers, slogHandler)
-	ml.outputs = append(ml.outputs, output)
+// Reopen reopens every file output at its configured path. Both the slog handler and the classic
+// logger of a sink write through the same file, so one reopen covers both.
+func (ml *modernLogger) Reopen() error {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
 
-	// Recreate slog logger with all handlers
-	ml.slog = slog.New(newMultiHandler(ml.handlers))
+	if ml.closed {
+		return nil
+	}
+	var errs []error
+	for _, output := range ml.outputs {
+		if reopener, ok := output.(interface{ Reopen() error }); ok {
+			if err := reopener.Reopen(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	return nil
+// Close closes every output the logger opened. It is safe to call more than once; log calls made
+// after Close are silently dropped.
+func (ml *modernLogger) Close() error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if ml.closed {
+		return nil
+	}
+	ml.closed = true
+
+	var errs []error
+	for _, output := range ml.outputs {
+		if closer, ok := output.(io.Closer); ok && output != os.Stdout {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // multiHandler is a slog.Handler that writes to multiple handlers
@@ -198,6 +383,131 @@ func (m *multiHandler) WithGroup(name string) slog.Handler {
 	return newMultiHandler(newHandlers)
 }
 
+// sinksHandler is the slog.Handler of a modernLogger. It fans records out to every sink of its core,
+// and replays the WithAttrs and WithGroup calls it was derived with on sinks added after that.
+type sinksHandler struct {
+	core  *loggerCore
+	ops   []func(slog.Handler) slog.Handler
+	cache atomic.Pointer[sinksHandlerCache]
+}
+
+// sinksHandlerCache holds the handlers derived for one generation of the core's sinks.
+type sinksHandlerCache struct {
+	generation uint64
+	handler    *multiHandler
+}
+
+// current returns the fan-out handler for the core's current structured sinks; classic sinks are
+// written by classicLogUnlocked instead. Log calls hold core.mu for reading, which keeps
+// core.handlers stable while it is rebuilt.
+func (h *sinksHandler) current() *multiHandler {
+	generation := h.core.generation.Load()
+	if cached := h.cache.Load(); cached != nil && cached.generation == generation {
+		return cached.handler
+	}
+	handlers := make([]slog.Handler, 0, len(h.core.handlers))
+	for i, handler := range h.core.handlers {
+		if !h.core.configs[i].Structured {
+			continue
+		}
+		for _, op := range h.ops {
+			handler = op(handler)
+		}
+		handlers = append(handlers, handler)
+	}
+	multi := newMultiHandler(handlers)
+	h.cache.Store(&sinksHandlerCache{generation: generation, handler: multi})
+	return multi
+}
+
+func (h *sinksHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.current().Enabled(ctx, level)
+}
+
+func (h *sinksHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.current().Handle(ctx, r)
+}
+
+func (h *sinksHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.derive(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *sinksHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.derive(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *sinksHandler) derive(op func(slog.Handler) slog.Handler) *sinksHandler {
+	return &sinksHandler{core: h.core, ops: append(slices.Clip(h.ops), op)}
+}
+
+// levelSetHandler enables exactly the levels listed in the sink's Levels (or ApiLevels for API
+// records), matching the classic path, instead of everything above a minimum level.
+type levelSetHandler struct {
+	inner  slog.Handler
+	config *LoggerConfig
+}
+
+// Enabled reads the level lists, which SetLevels replaces under the modernLogger write lock; log
+// calls hold the read lock while handling records. API records are checked at the level the sink
+// maps their status code to.
+func (h *levelSetHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if record, api := apiRecordFrom(ctx); api {
+		return !h.config.DisabledAPI && slices.Contains(h.config.ApiLevels, h.config.apiRecordLevel(record))
+	}
+	logLevel := fromSlogLevel(level)
+	if logLevel == FATAL {
+		return true
+	}
+	return !h.config.Disabled && slices.Contains(h.config.Levels, logLevel)
+}
+
+func (h *levelSetHandler) Handle(ctx context.Context, r slog.Record) error {
+	if record, api := apiRecordFrom(ctx); api {
+		r.Level = toSlogLevel(h.config.apiRecordLevel(record))
+		if h.config.isSlow(record) {
+			r.AddAttrs(slog.Bool("slow", true))
+		}
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *levelSetHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelSetHandler{inner: h.inner.WithAttrs(attrs), config: h.config}
+}
+
+func (h *levelSetHandler) WithGroup(name string) slog.Handler {
+	return &levelSetHandler{inner: h.inner.WithGroup(name), config: h.config}
+}
+
+// apiRecordKey marks the context of API records passed to slog handlers.
+type apiRecordKey struct{}
+
+// apiRecord describes an API access record.
+type apiRecord struct {
+	statusCode  int
+	requestPath string
+	request     *httpRequest  // set for records logged by Middleware
+	duration    time.Duration // how long the request took, 0 if unknown
+}
+
+func withAPIRecord(ctx context.Context, record *apiRecord) context.Context {
+	return context.WithValue(ctx, apiRecordKey{}, record)
+}
+
+func apiRecordFrom(ctx context.Context) (*apiRecord, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	record, ok := ctx.Value(apiRecordKey{}).(*apiRecord)
+	return record, ok
+}
+
 // apiPathFilterHandler skips slog records when attribute request_path matches exclude (per-sink API access filtering).
 type apiPathFilterHandler struct {
 	inner   slog.Handler
@@ -233,25 +543,6 @@ func (h *apiPathFilterHandler) WithGroup(name string) slog.Handler {
 	return &apiPathFilterHandler{inner: h.inner.WithGroup(name), exclude: h.exclude}
 }
 
-// convertLogLevelsToSlogLevel converts the logger levels to slog level
-func convertLogLevelsToSlogLevel(levels string) slog.Level {
-	levelStrs := SplitByMultiple(levels)
-	for _, levelStr := range levelStrs {
-		upperLevel := strings.ToUpper(levelStr)
-		switch upperLevel {
-		case "DEBUG":
-			return slog.LevelDebug
-		case "INFO", "INFO ":
-			return slog.LevelInfo
-		case "WARNING", "WARN", "WARN ":
-			return slog.LevelWarn
-		case "ERROR":
-			return slog.LevelError
-		}
-	}
-	return slog.LevelInfo
-}
-
 // stripProjectPath removes the project path from file paths for cleaner output
 func stripProjectPath(filePath string) string {
 	// Simple implementation - just return the filename
@@ -271,10 +562,17 @@ func stripFunctionPath(functionPath string) string {
 	return functionPath
 }
 
-// convertJsonConfigToLoggerConfig converts JsonConfig to LoggerConfig
-func convertJsonConfigToLoggerConfig(config JsonConfig) (*LoggerConfig, error) {
-	upperLevels := []LogLevel{}
-	for _, level := range SplitByMultiple(config.Levels) {
+// debugEnabled reports whether levels include DEBUG or TRACE, which add the level and file:line
+// to classic output.
+func debugEnabled(levels []LogLevel) bool {
+	return slices.Contains(levels, DEBUG) || slices.Contains(levels, TRACE)
+}
+
+// parseLevels parses a separated list of level names (eg. "info|warning|error") into LogLevels.
+// An empty list yields the default INFO, ERROR, WARNING.
+func parseLevels(levels string, api bool) ([]LogLevel, error) {
+	parsed := []LogLevel{}
+	for _, level := range SplitByMultiple(levels) {
 		if level == "" {
 			break
 		}
@@ -287,43 +585,93 @@ func convertJsonConfigToLoggerConfig(config JsonConfig) (*LoggerConfig, error) {
 		}
 		level, ok := stringToLevel[upperLevel]
 		if !ok {
+			level, ok = lookupCustomLevel(upperLevel)
+		}
+		if !ok {
+			if api {
+				return nil, fmt.Errorf("invalid api log level: %s", upperLevel)
+			}
 			return nil, fmt.Errorf("invalid log level: %s", upperLevel)
 		}
-		upperLevels = append(upperLevels, level)
+		parsed = append(parsed, level)
 	}
-	if len(upperLevels) == 0 {
-		upperLevels = []LogLevel{INFO, ERROR, WARNING}
+	if len(parsed) == 0 {
+		parsed = []LogLevel{INFO, ERROR, WARNING}
 	}
+	return parsed, nil
+}
 
-	upperApiLevels := []LogLevel{}
-	for _, level := range SplitByMultiple(config.ApiLevels) {
-		if level == "" {
-			break
+// convertJsonConfigToLoggerConfig converts JsonConfig to LoggerConfig
+func convertJsonConfigToLoggerConfig(config JsonConfig) (*LoggerConfig, error) {
+	upperLevels, err := parseLevels(config.Levels, false)
+	if err != nil {
+		return nil, err
+	}
+	upperApiLevels, err := parseLevels(config.ApiLevels, true)
+	if err != nil {
+		return nil, err
+	}
+
+	outputStdout := strings.ToUpper(config.Output)
+	if outputStdout == "STDOUT" {
+		config.Output = ""
+	}
+
+	format, err := parseFormat(config.Format, config.Json)
+	if err != nil {
+		return nil, err
+	}
+	if isGELFOutput(config.Output) {
+		if format != "" {
+			return nil, fmt.Errorf("invalid format: %s can't be used with a gelf output", format)
 		}
-		upperLevel := strings.ToUpper(level)
-		if upperLevel == "WARNING" || upperLevel == "WARN" {
-			upperLevel = "WARN "
+		if config.ApiFormat != "" {
+			return nil, fmt.Errorf("invalid apiFormat: %s can't be used with a gelf output", config.ApiFormat)
 		}
-		if upperLevel == "INFO" {
-			upperLevel = "INFO "
+		if config.MaxSize != 0 || config.MaxBackups != 0 || config.Rotate != "" || config.MaxAge != 0 {
+			return nil, fmt.Errorf("invalid gelf output: maxSize, maxBackups, rotate and maxAge only apply to files")
 		}
-		level, ok := stringToLevel[strings.ToUpper(upperLevel)]
-		if !ok {
-			return nil, fmt.Errorf("invalid api log level: %s", upperLevel)
+		if _, _, err := parseGELFOutput(config.Output, config.Compress); err != nil {
+			return nil, err
 		}
-		upperApiLevels = append(upperApiLevels, level)
+		format = formatGELF
 	}
-	if len(upperApiLevels) == 0 {
-		upperApiLevels = []LogLevel{INFO, ERROR, WARNING}
+	// JSON and other formats always enable structured logging, otherwise use the structured config (default: false)
+	structuredOutput := config.Json || config.Structured || format != ""
+
+	if config.MaxSize < 0 {
+		return nil, fmt.Errorf("invalid maxSize: %d", config.MaxSize)
+	}
+	if config.MaxBackups < 0 {
+		return nil, fmt.Errorf("invalid maxBackups: %d", config.MaxBackups)
+	}
+	if config.MaxAge < 0 {
+		return nil, fmt.Errorf("invalid maxAge: %d", config.MaxAge)
+	}
+	rotate := strings.ToLower(config.Rotate)
+	if rotate != "" && rotate != rotateDaily && rotate != rotateHourly {
+		return nil, fmt.Errorf("invalid rotate schedule: %s", config.Rotate)
 	}
 
-	outputStdout := strings.ToUpper(config.Output)
-	if outputStdout == "STDOUT" {
-		config.Output = ""
+	overflowPolicy := strings.ToLower(config.OverflowPolicy)
+	switch overflowPolicy {
+	case "", overflowBlock, overflowDropNewest, overflowDropOldest:
+	default:
+		return nil, fmt.Errorf("invalid overflowPolicy: %s", config.OverflowPolicy)
 	}
 
-	// JSON always enables structured logging, otherwise use the structured config (default: false)
-	structuredOutput := config.Json || config.Structured
+	apiStatusLevels, err := parseStatusLevels(config.ApiStatusLevels)
+	if err != nil {
+		return nil, err
+	}
+	apiFormat, err := parseApiFormat(config.ApiFormat)
+	if err != nil {
+		return nil, err
+	}
+	apiSlowThreshold, apiSlowPaths, err := parseSlowThresholds(config.ApiSlowThreshold, config.ApiSlowPaths)
+	if err != nil {
+		return nil, err
+	}
 
 	var apiPathExc *regexp.Regexp
 	if config.ApiPathExclude != "" {
@@ -340,172 +688,259 @@ func convertJsonConfigToLoggerConfig(config JsonConfig) (*LoggerConfig, error) {
 		Stdout:              config.Output == "",
 		Colors:              !config.NoColors,
 		Disabled:            slices.Contains(upperLevels, DISABLED),
-		DebugEnabled:        slices.Contains(upperLevels, DEBUG),
+		DebugEnabled:        debugEnabled(upperLevels),
 		DisabledAPI:         slices.Contains(upperApiLevels, DISABLED),
 		Utc:                 config.Utc,
 		FilePath:            config.Output,
 		Structured:          structuredOutput,
 		Json:                config.Json,
+		Format:              format,
 		ApiPathExcludeRegex: apiPathExc,
+		MaxSize:             config.MaxSize,
+		MaxBackups:          config.MaxBackups,
+		Rotate:              rotate,
+		MaxAge:              config.MaxAge,
+		Compress:            config.Compress,
+		Async:               config.Async,
+		BufferSize:          config.BufferSize,
+		OverflowPolicy:      overflowPolicy,
+		ApiStatusLevels:     apiStatusLevels,
+		ApiFormat:           apiFormat,
+		ApiSlowThreshold:    apiSlowThreshold,
+		ApiSlowPaths:        apiSlowPaths,
+		ContextExtractors:   config.ContextExtractors,
 	}, nil
 }
 
 // Basic logging methods
+func (ml *modernLogger) Trace(msg string, args ...any) {
+	ml.logWithLevel(TRACE, msg, false, nil, args...)
+}
+
 func (ml *modernLogger) Debug(msg string, args ...any) {
-	ml.logWithLevel(DEBUG, msg, false, false, "", args...)
+	ml.logWithLevel(DEBUG, msg, false, nil, args...)
 }
 
 func (ml *modernLogger) Info(msg string, args ...any) {
-	ml.logWithLevel(INFO, msg, false, false, "", args...)
+	ml.logWithLevel(INFO, msg, false, nil, args...)
 }
 
 func (ml *modernLogger) Warn(msg string, args ...any) {
-	ml.logWithLevel(WARNING, msg, false, false, "", args...)
+	ml.logWithLevel(WARNING, msg, false, nil, args...)
 }
 
 func (ml *modernLogger) Error(msg string, args ...any) {
-	ml.logWithLevel(ERROR, msg, false, false, "", args...)
+	ml.logWithLevel(ERROR, msg, false, nil, args...)
 }
 
 func (ml *modernLogger) Fatal(msg string, args ...any) {
-	ml.logWithLevel(FATAL, msg, false, false, "", args...)
+	ml.logWithLevel(FATAL, msg, false, nil, args...)
 }
 
 // Formatted logging methods
+func (ml *modernLogger) Tracef(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	ml.logWithLevel(TRACE, msg, true, nil)
+}
+
 func (ml *modernLogger) Debugf(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevel(DEBUG, msg, true, false, "")
+	ml.logWithLevel(DEBUG, msg, true, nil)
 }
 
 func (ml *modernLogger) Infof(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevel(INFO, msg, true, false, "")
+	ml.logWithLevel(INFO, msg, true, nil)
 }
 
 func (ml *modernLogger) Warnf(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevel(WARNING, msg, true, false, "")
+	ml.logWithLevel(WARNING, msg, true, nil)
 }
 
 func (ml *modernLogger) Errorf(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevel(ERROR, msg, true, false, "")
+	ml.logWithLevel(ERROR, msg, true, nil)
 }
 
 func (ml *modernLogger) Fatalf(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevel(FATAL, msg, true, false, "")
+	ml.logWithLevel(FATAL, msg, true, nil)
 }
 
 // Context-aware methods
+func (ml *modernLogger) TraceContext(ctx context.Context, msg string, args ...any) {
+	ml.logWithLevelAndContext(TRACE, msg, false, ctx, nil, args...)
+}
+
 func (ml *modernLogger) DebugContext(ctx context.Context, msg string, args ...any) {
-	ml.logWithLevelAndContext(DEBUG, msg, false, false, ctx, "", args...)
+	ml.logWithLevelAndContext(DEBUG, msg, false, ctx, nil, args...)
 }
 
 func (ml *modernLogger) InfoContext(ctx context.Context, msg string, args ...any) {
-	ml.logWithLevelAndContext(INFO, msg, false, false, ctx, "", args...)
+	ml.logWithLevelAndContext(INFO, msg, false, ctx, nil, args...)
 }
 
 func (ml *modernLogger) WarnContext(ctx context.Context, msg string, args ...any) {
-	ml.logWithLevelAndContext(WARNING, msg, false, false, ctx, "", args...)
+	ml.logWithLevelAndContext(WARNING, msg, false, ctx, nil, args...)
 }
 
 func (ml *modernLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
-	ml.logWithLevelAndContext(ERROR, msg, false, false, ctx, "", args...)
+	ml.logWithLevelAndContext(ERROR, msg, false, ctx, nil, args...)
 }
 
 func (ml *modernLogger) FatalContext(ctx context.Context, msg string, args ...any) {
-	ml.logWithLevelAndContext(FATAL, msg, false, false, ctx, "", args...)
+	ml.logWithLevelAndContext(FATAL, msg, false, ctx, nil, args...)
 }
 
 // Formatted context-aware methods
+func (ml *modernLogger) TracefContext(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	ml.logWithLevelAndContext(TRACE, msg, true, ctx, nil)
+}
+
 func (ml *modernLogger) DebugfContext(ctx context.Context, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevelAndContext(DEBUG, msg, true, false, ctx, "")
+	ml.logWithLevelAndContext(DEBUG, msg, true, ctx, nil)
 }
 
 func (ml *modernLogger) InfofContext(ctx context.Context, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevelAndContext(INFO, msg, true, false, ctx, "")
+	ml.logWithLevelAndContext(INFO, msg, true, ctx, nil)
 }
 
 func (ml *modernLogger) WarnfContext(ctx context.Context, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevelAndContext(WARNING, msg, true, false, ctx, "")
+	ml.logWithLevelAndContext(WARNING, msg, true, ctx, nil)
 }
 
 func (ml *modernLogger) ErrorfContext(ctx context.Context, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevelAndContext(ERROR, msg, true, false, ctx, "")
+	ml.logWithLevelAndContext(ERROR, msg, true, ctx, nil)
 }
 
 func (ml *modernLogger) FatalfContext(ctx context.Context, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logWithLevelAndContext(FATAL, msg, true, false, ctx, "")
+	ml.logWithLevelAndContext(FATAL, msg, true, ctx, nil)
+}
+
+// Log logs at any level, including levels added with RegisterLevel.
+func (ml *modernLogger) Log(ctx context.Context, level LogLevel, msg string, args ...any) {
+	// always show the level name in classic output, it is what tells custom levels apart
+	ml.logWithLevelAndContext(level, msg, true, ctx, nil, args...)
 }
 
 // Structured logging
-func (ml *modernLogger) With(args ...any) Logger {
-	ml.mu.RLock()
-	defer ml.mu.RUnlock()
 
-	newLogger := &modernLogger{
-		configs: make([]*LoggerConfig, len(ml.configs)),
-		slog:    ml.slog.With(args...),
+// With returns a child logger that adds args to every record, in structured and classic output
+// alike. The child shares its parent's sinks, levels and lifecycle.
+func (ml *modernLogger) With(args ...any) Logger {
+	attrs := slices.Clip(ml.attrs)
+	for _, attr := range argsToAttrs(args) {
+		attrs = append(attrs, nestInGroups(ml.groups, attr))
+	}
+	return &modernLogger{
+		loggerCore: ml.loggerCore,
+		slog:       ml.slog.With(args...),
+		attrs:      attrs,
+		groups:     ml.groups,
 	}
-	copy(newLogger.configs, ml.configs)
-	return newLogger
 }
 
+// WithGroup returns a child logger that nests the attributes bound after it, and those of each
+// record, under name.
 func (ml *modernLogger) WithGroup(name string) Logger {
-	ml.mu.RLock()
-	defer ml.mu.RUnlock()
+	groups := ml.groups
+	if name != "" {
+		groups = append(slices.Clip(groups), name)
+	}
+	return &modernLogger{
+		loggerCore: ml.loggerCore,
+		slog:       ml.slog.WithGroup(name),
+		attrs:      ml.attrs,
+		groups:     groups,
+	}
+}
+
+// argsToAttrs converts key-value pairs and slog.Attrs to attributes the way slog.Logger.With does.
+func argsToAttrs(args []any) []slog.Attr {
+	var record slog.Record
+	record.Add(args...)
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
 
-	newLogger := &modernLogger{
-		configs: make([]*LoggerConfig, len(ml.configs)),
-		slog:    ml.slog.WithGroup(name),
+// nestInGroups wraps attr in the given groups, outermost first.
+func nestInGroups(groups []string, attr slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		attr = slog.Attr{Key: groups[i], Value: slog.GroupValue(attr)}
 	}
-	copy(newLogger.configs, ml.configs)
-	return newLogger
+	return attr
+}
+
+// classicAttrs formats the bound attributes as key=value parts of classic lines.
+func (ml *modernLogger) classicAttrs() []string {
+	var parts []string
+	for _, attr := range ml.attrs {
+		parts = appendAttr(parts, "", attr)
+	}
+	return parts
 }
 
 // API logging
 func (ml *modernLogger) API(statusCode int, msg string, args ...any) {
-	ml.logAPI(statusCode, msg, false, args...)
+	ml.logAPI(&apiRecord{statusCode: statusCode}, msg, false, args...)
 }
 
 // APIPath is like API but supplies requestPath (path plus raw query) for per-sink ApiPathExclude filtering.
 func (ml *modernLogger) APIPath(statusCode int, requestPath string, msg string, args ...any) {
-	ml.logAPIWithPath(statusCode, requestPath, msg, false, args...)
+	ml.logAPI(&apiRecord{statusCode: statusCode, requestPath: requestPath}, msg, false, args...)
 }
 
 func (ml *modernLogger) APIf(statusCode int, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logAPI(statusCode, msg, false) // API logs don't show level prefix
+	ml.logAPI(&apiRecord{statusCode: statusCode}, msg, false) // API logs don't show level prefix
 }
 
 func (ml *modernLogger) APIContext(ctx context.Context, statusCode int, msg string, args ...any) {
-	ml.logAPIWithContext(statusCode, msg, false, ctx, args...)
+	ml.logAPIWithContext(&apiRecord{statusCode: statusCode}, msg, false, ctx, args...)
 }
 
 func (ml *modernLogger) APIfContext(ctx context.Context, statusCode int, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	ml.logAPIWithContext(statusCode, msg, false, ctx) // API logs don't show level prefix
+	ml.logAPIWithContext(&apiRecord{statusCode: statusCode}, msg, false, ctx) // API logs don't show level prefix
+}
+
+// APIPathContext is like APIPath but also takes a context, for ContextExtractors and trace correlation.
+func (ml *modernLogger) APIPathContext(ctx context.Context, statusCode int, requestPath string, msg string, args ...any) {
+	ml.logAPIWithContext(&apiRecord{statusCode: statusCode, requestPath: requestPath}, msg, false, ctx, args...)
 }
 
 // Internal logging methods
 
-// classicLogUnlocked writes to per-config outputs. Caller must hold ml.mu RLock.
-func (ml *modernLogger) classicLogUnlocked(level LogLevel, msg string, formatted bool, api bool, apiPath string) {
-	levelStr := levelToString(level)
-	color := getColorForLevel(level)
+// classicLogUnlocked writes to the outputs of classic (non-structured) configs, appending the bound
+// attributes and the context attributes of each config (see extractContextAttrs) to msg. Caller must
+// hold ml.mu RLock.
+func (ml *modernLogger) classicLogUnlocked(ctx context.Context, level LogLevel, msg string, formatted bool, api *apiRecord) {
+	attrs := ml.classicAttrs()
 
 	for _, config := range ml.configs {
-		if api {
+		if config.Structured {
+			// written through ml.slog
+			continue
+		}
+		level := level
+		if api != nil {
+			// each sink maps the status code and latency to a level of its own
+			level = config.apiRecordLevel(api)
 			if config.DisabledAPI || !slices.Contains(config.ApiLevels, level) {
 				continue
 			}
-			if apiPath != "" && config.ApiPathExcludeRegex != nil && config.ApiPathExcludeRegex.MatchString(apiPath) {
+			if api.requestPath != "" && config.ApiPathExcludeRegex != nil && config.ApiPathExcludeRegex.MatchString(api.requestPath) {
 				continue
 			}
 		} else if level != FATAL {
@@ -514,83 +949,121 @@ func (ml *modernLogger) classicLogUnlocked(level LogLevel, msg string, formatted
 			}
 		}
 
-		ml.writeToConfig(config, levelStr, msg, formatted, api, color)
+		if api != nil && config.ApiFormat != "" {
+			if err := config.writeAccessLine(api); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to log message '%v' with error `%v`\n", msg, err)
+			}
+			continue
+		}
+
+		parts := append(slices.Clip(attrs), contextAttrParts(ctx, config.ContextExtractors)...)
+		if api != nil && config.isSlow(api) {
+			parts = append(parts, "slow=true")
+		}
+		line := msg
+		if len(parts) > 0 {
+			line += " " + joinAttrs(parts)
+		}
+		ml.writeToConfig(config, levelToString(level), line, formatted, api != nil, getColorForLevel(level))
 	}
 }
 
-func (ml *modernLogger) logWithLevel(level LogLevel, msg string, formatted bool, api bool, apiPath string, args ...any) {
+// logWithLevel logs msg at level, or as an API access record when api is set.
+func (ml *modernLogger) logWithLevel(level LogLevel, msg string, formatted bool, api *apiRecord, args ...any) {
+	// deferred first so it runs after the read lock is released, as exitAfterFatal closes the outputs.
+	// FATAL exits even when the logger is closed and the record is dropped.
+	fatal := level == FATAL
+	defer func() {
+		if fatal {
+			ml.exitAfterFatal()
+		}
+	}()
 	ml.mu.RLock()
 	defer ml.mu.RUnlock()
 
-	if len(ml.configs) == 0 {
+	if len(ml.configs) == 0 || ml.closed {
 		return
 	}
 
-	// Structured logging (request_path is added for per-handler API path filtering)
-	if ml.configs[0].Structured {
-		var attrs []any
-		if apiPath != "" {
-			attrs = append(attrs, "request_path", apiPath)
-		}
-		attrs = append(attrs, args...)
-		if len(attrs) > 0 {
-			ml.slogStructuredLog(level, msg, attrs...)
-		} else {
-			ml.slogStructuredLog(level, msg)
-		}
-		return
+	// Each sink renders in its own mode: structured sinks through slog (request_path is added for
+	// per-handler API path filtering), classic sinks through their log.Logger
+	var attrs []any
+	if api != nil && api.requestPath != "" {
+		attrs = append(attrs, "request_path", api.requestPath)
 	}
-
-	ml.classicLogUnlocked(level, msg, formatted, api, apiPath)
-
-	if level == FATAL {
-		os.Exit(1)
+	attrs = append(attrs, args...)
+	if api != nil {
+		// API records are marked so each sink maps and filters them against its own levels
+		ml.slogStructuredLogWithContext(withAPIRecord(context.Background(), api), level, msg, attrs...)
+	} else {
+		ml.slogStructuredLog(level, msg, attrs...)
 	}
+
+	ml.classicLogUnlocked(context.Background(), level, msg, formatted, api)
 }
 
-func (ml *modernLogger) logWithLevelAndContext(level LogLevel, msg string, formatted bool, api bool, ctx context.Context, apiPath string, args ...any) {
+func (ml *modernLogger) logWithLevelAndContext(level LogLevel, msg string, formatted bool, ctx context.Context, api *apiRecord, args ...any) {
+	// deferred first so it runs after the read lock is released, as exitAfterFatal closes the outputs.
+	// FATAL exits even when the logger is closed and the record is dropped.
+	fatal := level == FATAL
+	defer func() {
+		if fatal {
+			ml.exitAfterFatal()
+		}
+	}()
 	ml.mu.RLock()
 	defer ml.mu.RUnlock()
 
-	if len(ml.configs) == 0 {
+	if len(ml.configs) == 0 || ml.closed {
 		return
 	}
 
-	// Use structured logging with context if enabled
-	if ml.configs[0].Structured {
-		var attrs []any
-		if apiPath != "" {
-			attrs = append(attrs, "request_path", apiPath)
-		}
-		attrs = append(attrs, args...)
-		if len(attrs) > 0 {
-			ml.slogStructuredLogWithContext(ctx, level, msg, attrs...)
-		} else {
-			ml.slogStructuredLogWithContext(ctx, level, msg)
-		}
-		return
+	// attributes bound to a logger carried by ctx (see NewContext) describe the request, so they are
+	// added at the top level along with the extracted context attributes
+	if extra := ml.contextAttrs(ctx); len(extra) > 0 {
+		ctx = withLoggerAttrs(ctx, extra)
 	}
 
-	ml.classicLogUnlocked(level, msg, formatted, api, apiPath)
-
-	if level == FATAL {
-		os.Exit(1)
+	// Structured sinks get the record with context, classic sinks the same message as text
+	var attrs []any
+	if api != nil && api.requestPath != "" {
+		attrs = append(attrs, "request_path", api.requestPath)
 	}
+	attrs = append(attrs, args...)
+	slogCtx := ctx
+	if api != nil {
+		slogCtx = withAPIRecord(ctx, api)
+	}
+	ml.slogStructuredLogWithContext(slogCtx, level, msg, attrs...)
+
+	ml.classicLogUnlocked(ctx, level, msg, formatted, api)
 }
 
-func (ml *modernLogger) logAPI(statusCode int, msg string, formatted bool, args ...any) {
-	level, _ := getAPILevelAndColor(statusCode)
-	ml.logWithLevel(level, msg, formatted, true, "", args...)
+// exitProcess ends the process after a FATAL record; tests replace it.
+var exitProcess = os.Exit
+
+// exitAfterFatal closes the outputs, so async sinks write what they still queue, including the FATAL
+// record, and exits with status 1. Caller must not hold ml.mu.
+func (ml *modernLogger) exitAfterFatal() {
+	if err := ml.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to close outputs before exiting with error `%v`\n", err)
+	}
+	exitProcess(1)
 }
 
-func (ml *modernLogger) logAPIWithPath(statusCode int, requestPath string, msg string, formatted bool, args ...any) {
-	level, _ := getAPILevelAndColor(statusCode)
-	ml.logWithLevel(level, msg, formatted, true, requestPath, args...)
+// logAPI logs an API record at the default level for its status code; sinks with ApiStatusLevels
+// map it to their own.
+func (ml *modernLogger) logAPI(api *apiRecord, msg string, formatted bool, args ...any) {
+	api.duration = apiDuration(nil, formatted, args)
+	level, _ := getAPILevelAndColor(api.statusCode)
+	ml.logWithLevel(level, msg, formatted, api, args...)
 }
 
-func (ml *modernLogger) logAPIWithContext(statusCode int, msg string, formatted bool, ctx context.Context, args ...any) {
-	level, _ := getAPILevelAndColor(statusCode)
-	ml.logWithLevelAndContext(level, msg, formatted, true, ctx, "", args...)
+func (ml *modernLogger) logAPIWithContext(api *apiRecord, msg string, formatted bool, ctx context.Context, args ...any) {
+	api.request = httpRequestFrom(ctx)
+	api.duration = apiDuration(api.request, formatted, args)
+	level, _ := getAPILevelAndColor(api.statusCode)
+	ml.logWithLevelAndContext(level, msg, formatted, ctx, api, args...)
 }
 
 func (ml *modernLogger) slogStructuredLog(level LogLevel, msg string, args ...any) {
@@ -604,6 +1077,8 @@ func (ml *modernLogger) slogStructuredLog(level LogLevel, msg string, args ...an
 
 	// Use slog for structured logging
 	switch level {
+	case TRACE:
+		ml.slog.Log(context.Background(), slogLevelTrace, msg, attrs...)
 	case DEBUG:
 		ml.slog.Debug(msg, attrs...)
 	case INFO:
@@ -613,8 +1088,7 @@ func (ml *modernLogger) slogStructuredLog(level LogLevel, msg string, args ...an
 	case ERROR:
 		ml.slog.Error(msg, attrs...)
 	case FATAL:
-		ml.slog.Error(msg, attrs...)
-		os.Exit(1)
+		ml.slog.Log(context.Background(), slogLevelFatal, msg, attrs...)
 	}
 }
 
@@ -629,6 +1103,8 @@ func (ml *modernLogger) slogStructuredLogWithContext(ctx context.Context, level
 
 	// Use slog for structured logging with context
 	switch level {
+	case TRACE:
+		ml.slog.Log(ctx, slogLevelTrace, msg, attrs...)
 	case DEBUG:
 		ml.slog.DebugContext(ctx, msg, attrs...)
 	case INFO:
@@ -638,8 +1114,9 @@ func (ml *modernLogger) slogStructuredLogWithContext(ctx context.Context, level
 	case ERROR:
 		ml.slog.ErrorContext(ctx, msg, attrs...)
 	case FATAL:
-		ml.slog.ErrorContext(ctx, msg, attrs...)
-		os.Exit(1)
+		ml.slog.Log(ctx, slogLevelFatal, msg, attrs...)
+	default:
+		ml.slog.Log(ctx, toSlogLevel(level), msg, attrs...)
 	}
 }
 
@@ -673,9 +1150,75 @@ func (ml *modernLogger) writeToConfig(config *LoggerConfig, levelStr, msg string
 	}
 }
 
+// slog levels for LogLevels slog has no constant for
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelFatal = slog.LevelError + 4
+	// slogLevelAll is the minimum level of a sink's base handler, which handles every record its
+	// levelSetHandler lets through
+	slogLevelAll = slog.Level(math.MinInt)
+)
+
+// toSlogLevel converts a LogLevel to the slog level it is logged at.
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case TRACE:
+		return slogLevelTrace
+	case DEBUG:
+		return slog.LevelDebug
+	case WARNING:
+		return slog.LevelWarn
+	case ERROR:
+		return slog.LevelError
+	case FATAL:
+		return slogLevelFatal
+	}
+	if custom, ok := customLevelOf(level); ok {
+		return custom.severity
+	}
+	return slog.LevelInfo
+}
+
+// fromSlogLevel converts a slog level back to the LogLevel it was logged at.
+func fromSlogLevel(level slog.Level) LogLevel {
+	if custom, _, ok := customLevelAt(level); ok {
+		return custom
+	}
+	switch {
+	case level >= slogLevelFatal:
+		return FATAL
+	case level >= slog.LevelError:
+		return ERROR
+	case level >= slog.LevelWarn:
+		return WARNING
+	case level >= slog.LevelInfo:
+		return INFO
+	case level >= slog.LevelDebug:
+		return DEBUG
+	default:
+		return TRACE
+	}
+}
+
+// slogLevelLabel names a slog level in structured output, spelling out levels slog has no name for.
+func slogLevelLabel(level slog.Level) string {
+	switch level {
+	case slogLevelTrace:
+		return "TRACE"
+	case slogLevelFatal:
+		return "FATAL"
+	}
+	if _, custom, ok := customLevelAt(level); ok {
+		return custom.name
+	}
+	return level.String()
+}
+
 // Helper functions
 func levelToString(level LogLevel) string {
 	switch level {
+	case TRACE:
+		return "TRACE"
 	case DEBUG:
 		return "DEBUG"
 	case INFO:
@@ -688,14 +1231,18 @@ func levelToString(level LogLevel) string {
 		return "FATAL"
 	case API:
 		return "API"
-	default:
-		return "UNKNOWN"
+	case DISABLED:
+		return "DISABLED"
+	}
+	if custom, ok := customLevelOf(level); ok {
+		return custom.name
 	}
+	return "UNKNOWN"
 }
 
 func getColorForLevel(level LogLevel) string {
 	switch level {
-	case DEBUG:
+	case TRACE, DEBUG:
 		return GRAY
 	case WARNING:
 		return YELLOW
@@ -703,9 +1250,11 @@ func getColorForLevel(level LogLevel) string {
 		return RED
 	case INFO:
 		return ""
-	default:
-		return ""
 	}
+	if custom, ok := customLevelOf(level); ok {
+		return custom.color
+	}
+	return ""
 }
 
 func getAPILevelAndColor(statusCode int) (LogLevel, string) {
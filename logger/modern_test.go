@@ -3,8 +3,11 @@ package logger
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -154,6 +157,141 @@ func TestModernLogger_WithGroup(t *testing.T) {
 	}
 }
 
+func TestModernLogger_WithChildrenShareSinks(t *testing.T) {
+	dir := t.TempDir()
+	classicPath := filepath.Join(dir, "classic.log")
+	jsonPath := filepath.Join(dir, "json.log")
+	addedPath := filepath.Join(dir, "added.log")
+
+	classic, err := NewLogger(JsonConfig{Levels: "INFO", Output: classicPath, NoColors: true})
+	if err != nil {
+		t.Fatalf("Failed to create classic logger: %v", err)
+	}
+	defer classic.Close()
+	classic.With("user_id", 7).WithGroup("req").With("id", "r1").Info("classic line")
+
+	got, err := os.ReadFile(classicPath)
+	if err != nil {
+		t.Fatalf("Failed to read classic log: %v", err)
+	}
+	if !strings.Contains(string(got), "classic line user_id=7 req.id=r1") {
+		t.Errorf("Expected bound attributes as a suffix of the classic line, got: %s", got)
+	}
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", Output: jsonPath, Json: true})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	child := logger.With("user_id", 7).WithGroup("req")
+
+	// a sink added to the parent after the child was created receives the child's records too
+	if err := logger.(*modernLogger).addConfig(JsonConfig{Levels: "INFO", Output: addedPath, Json: true}); err != nil {
+		t.Fatalf("Failed to add sink: %v", err)
+	}
+	child.Info("json line", "id", "r1")
+
+	for _, path := range []string{jsonPath, addedPath} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(got, &entry); err != nil {
+			t.Fatalf("Expected one JSON line in %s, got: %s", path, got)
+		}
+		req, _ := entry["req"].(map[string]any)
+		if entry["user_id"] != float64(7) || req["id"] != "r1" {
+			t.Errorf("Expected user_id and req.id in %s, got: %s", path, got)
+		}
+	}
+
+	// closing the child closes the sinks it shares with the parent
+	if err := child.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	logger.Info("after close")
+	got, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log: %v", err)
+	}
+	if strings.Contains(string(got), "after close") {
+		t.Errorf("Expected parent to be closed along with the child, got: %s", got)
+	}
+}
+
+func TestModernLogger_MixedSinkModes(t *testing.T) {
+	dir := t.TempDir()
+	classicPath := filepath.Join(dir, "classic.log")
+	jsonPath := filepath.Join(dir, "json.log")
+
+	for _, first := range []string{"classic", "json"} {
+		classicConfig := JsonConfig{Levels: "INFO", ApiLevels: "INFO|WARNING", Output: classicPath, NoColors: true}
+		jsonConfig := JsonConfig{Levels: "INFO", ApiLevels: "INFO|WARNING", Output: jsonPath, Json: true}
+		configs := []JsonConfig{classicConfig, jsonConfig}
+		if first == "json" {
+			configs = []JsonConfig{jsonConfig, classicConfig}
+		}
+		_ = os.Remove(classicPath)
+		_ = os.Remove(jsonPath)
+
+		logger, err := NewLogger(configs[0])
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		if err := logger.(*modernLogger).addConfig(configs[1]); err != nil {
+			t.Fatalf("Failed to add sink: %v", err)
+		}
+		logger.With("user_id", 7).InfoContext(context.Background(), "mixed line", "id", "r1")
+		logger.API(404, "GET /missing")
+		_ = logger.Close()
+
+		got, err := os.ReadFile(classicPath)
+		if err != nil {
+			t.Fatalf("Failed to read classic log: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+		if len(lines) != 2 || !strings.HasSuffix(lines[0], "mixed line user_id=7") || !strings.Contains(lines[1], "GET /missing") {
+			t.Errorf("Expected two classic lines with %s sink first, got: %s", first, got)
+		}
+
+		got, err = os.ReadFile(jsonPath)
+		if err != nil {
+			t.Fatalf("Failed to read JSON log: %v", err)
+		}
+		lines = strings.Split(strings.TrimSpace(string(got)), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected two JSON lines with %s sink first, got: %s", first, got)
+		}
+		var entry, access map[string]any
+		if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil || entry["msg"] != "mixed line" || entry["user_id"] != float64(7) {
+			t.Errorf("Expected a JSON record with user_id with %s sink first, got: %s", first, lines[0])
+		}
+		if err := json.Unmarshal([]byte(lines[1]), &access); err != nil || access["level"] != "WARN" || access["request_path"] != nil {
+			t.Errorf("Expected a JSON API record with %s sink first, got: %s", first, lines[1])
+		}
+	}
+}
+
+func TestCustomHandler_WithAttrsAndGroups(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewCustomHandler(&buf, slog.LevelDebug, &LoggerConfig{})
+
+	log := slog.New(handler).With("user_id", 1).WithGroup("request").With("id", "req-1")
+	log.Info("Handled", "path", "/api", slog.Group("client", "ip", "10.0.0.1"))
+	slog.New(handler).Info("Unaffected parent")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got: %s", buf.String())
+	}
+	if want := "Handled user_id=1 request.id=req-1 request.path=/api request.client.ip=10.0.0.1"; !strings.HasSuffix(lines[0], want) {
+		t.Errorf("Expected line ending in '%s', got: %s", want, lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "Unaffected parent") {
+		t.Errorf("Expected parent handler without attributes, got: %s", lines[1])
+	}
+}
+
 func TestModernLogger_API_Logging(t *testing.T) {
 	var buf bytes.Buffer
 
@@ -271,6 +409,7 @@ func TestNoOpLogger(t *testing.T) {
 
 	// These should not panic
 	noOp.Info("test")
+	noOp.Trace("test")
 	noOp.Debug("test")
 	noOp.Warn("test")
 	noOp.Error("test")
@@ -299,6 +438,14 @@ func TestNoOpLogger(t *testing.T) {
 	noOp.APIf(200, "test %s", "value")
 	noOp.APIContext(ctx, 200, "test")
 	noOp.APIfContext(ctx, 200, "test %s", "value")
+	noOp.APIPathContext(ctx, 200, "/test", "test")
+
+	if err := noOp.Sync(); err != nil {
+		t.Errorf("Sync should be a no-op, got: %v", err)
+	}
+	if err := noOp.Close(); err != nil {
+		t.Errorf("Close should be a no-op, got: %v", err)
+	}
 
 	// With methods should return the same no-op logger
 	result := noOp.With("key", "value")
@@ -312,6 +459,281 @@ func TestNoOpLogger(t *testing.T) {
 	}
 }
 
+func TestModernLogger_CloseAndSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger, err := NewLogger(JsonConfig{
+		Levels:   "INFO",
+		Output:   path,
+		NoColors: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	ml := logger.(*modernLogger)
+	if err := ml.addConfig(JsonConfig{Levels: "INFO", Output: path, Json: true}); err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+
+	logger.Info("before close")
+	if err := logger.Sync(); err != nil {
+		t.Errorf("Sync failed: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Second Close should be a no-op, got: %v", err)
+	}
+
+	for _, output := range ml.outputs {
+		if file := output.(*rotatingFile); file.file != nil {
+			t.Errorf("Expected %s to be closed", file.path)
+		}
+	}
+
+	// must not panic or write anything
+	logger.Info("after close")
+	logger.API(200, "after close")
+	if err := ml.addConfig(JsonConfig{Levels: "INFO"}); err == nil {
+		t.Error("Expected addConfig on a closed logger to fail")
+	}
+
+	// FATAL is dropped too, but still ends the process
+	exits := 0
+	exitProcess = func(code int) { exits++ }
+	defer func() { exitProcess = os.Exit }()
+	logger.Fatal("after close")
+	logger.Fatalf("after %s", "close")
+	logger.FatalContext(context.Background(), "after close")
+	if exits != 3 {
+		t.Errorf("Expected FATAL on a closed logger to exit 3 times, got %d", exits)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "before close") {
+		t.Errorf("Expected 'before close' in log file, got: %s", data)
+	}
+	if strings.Contains(string(data), "after close") {
+		t.Errorf("Expected no writes after Close, got: %s", data)
+	}
+}
+
+func TestModernLogger_SetLevels(t *testing.T) {
+	var classicBuf bytes.Buffer
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", ApiLevels: "ERROR", NoColors: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	ml := logger.(*modernLogger)
+	ml.configs[0].logger.SetOutput(&classicBuf)
+	jsonPath := filepath.Join(t.TempDir(), "json.log")
+	if err := ml.addConfig(JsonConfig{Levels: "INFO", ApiLevels: "ERROR", Output: jsonPath, Json: true}); err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("hidden debug")
+	logger.API(200, "hidden api")
+	if err := logger.SetLevels("DEBUG,INFO"); err != nil {
+		t.Fatalf("SetLevels failed: %v", err)
+	}
+	if err := logger.SetApiLevels("INFO"); err != nil {
+		t.Fatalf("SetApiLevels failed: %v", err)
+	}
+	logger.Debug("visible debug")
+	logger.API(200, "visible api")
+
+	classic := classicBuf.String()
+	if strings.Contains(classic, "hidden") {
+		t.Errorf("Expected nothing logged before SetLevels, got: %s", classic)
+	}
+	if !strings.Contains(classic, "visible debug") || !strings.Contains(classic, "visible api") {
+		t.Errorf("Expected debug and api lines after SetLevels, got: %s", classic)
+	}
+	if !ml.configs[0].DebugEnabled {
+		t.Error("Expected DebugEnabled after enabling DEBUG")
+	}
+	if got, _ := os.ReadFile(jsonPath); strings.Contains(string(got), "hidden") || !strings.Contains(string(got), "visible debug") || !strings.Contains(string(got), "visible api") {
+		t.Errorf("Expected SetLevels to apply to the JSON sink too, got: %s", got)
+	}
+
+	if err := logger.SetLevels("LOUD"); err == nil {
+		t.Error("Expected error for invalid level, got nil")
+	}
+
+	// level changes must be safe alongside in-flight log calls
+	for _, cfg := range ml.configs {
+		cfg.logger.SetOutput(io.Discard)
+	}
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			logger.Debug("concurrent", "iteration", i)
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		_ = logger.SetLevels([]string{"INFO", "DEBUG"}[i%2])
+	}
+	<-done
+}
+
+func TestModernLogger_LevelSetSemantics(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config JsonConfig
+	}{
+		{"classic", JsonConfig{}},
+		{"structured", JsonConfig{Structured: true}},
+		{"json", JsonConfig{Json: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			config := tc.config
+			config.Levels = "ERROR,DEBUG"
+			config.ApiLevels = "WARNING"
+			config.Output = path
+			config.NoColors = true
+
+			logger, err := NewLogger(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			logger.Debug("debug emitted")
+			logger.Info("info suppressed")
+			logger.Warn("warn suppressed")
+			logger.Error("error emitted")
+			logger.API(200, "api info suppressed")
+			logger.API(404, "api warn emitted")
+			logger.API(500, "api error suppressed")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+			output := string(data)
+			for _, want := range []string{"debug emitted", "error emitted", "api warn emitted"} {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected '%s' in output, got: %s", want, output)
+				}
+			}
+			if strings.Contains(output, "suppressed") {
+				t.Errorf("Expected only the configured level set in output, got: %s", output)
+			}
+		})
+	}
+}
+
+func TestModernLogger_TraceLevel(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config JsonConfig
+		want   string
+	}{
+		{"classic", JsonConfig{}, "[TRACE] "},
+		{"structured", JsonConfig{Structured: true}, "[TRACE] modern_test.go:"},
+		{"json", JsonConfig{Json: true}, `"level":"TRACE"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			config := tc.config
+			config.Levels = "TRACE,INFO"
+			config.Output = path
+			config.NoColors = true
+
+			logger, err := NewLogger(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			logger.Trace("wire dump", "bytes", 42)
+			logger.Debug("debug suppressed")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+			output := string(data)
+			if !strings.Contains(output, "wire dump") || !strings.Contains(output, tc.want) {
+				t.Errorf("Expected TRACE line containing '%s', got: %s", tc.want, output)
+			}
+			if strings.Contains(output, "debug suppressed") {
+				t.Errorf("Expected DEBUG to stay disabled, got: %s", output)
+			}
+		})
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	notice, err := RegisterLevel("notice", 2, GREEN)
+	if err != nil {
+		t.Fatalf("Failed to register level: %v", err)
+	}
+	if again, err := RegisterLevel("NOTICE", 2, GREEN); err != nil || again != notice {
+		t.Errorf("Expected re-registering NOTICE to return the same level, got %v, %v", again, err)
+	}
+	for _, tc := range []struct {
+		name     string
+		severity slog.Level
+	}{
+		{"INFO", 1},
+		{"AUDIT", slog.LevelWarn},
+		{"SECURITY", 2},
+		{"two words", 3},
+	} {
+		if _, err := RegisterLevel(tc.name, tc.severity, ""); err == nil {
+			t.Errorf("Expected RegisterLevel(%q, %d) to fail", tc.name, tc.severity)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		config JsonConfig
+		want   string
+	}{
+		{"classic", JsonConfig{}, "[NOTICE] "},
+		{"structured", JsonConfig{Structured: true}, "[NOTICE] "},
+		{"json", JsonConfig{Json: true}, `"level":"NOTICE"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			config := tc.config
+			config.Levels = "NOTICE,ERROR"
+			config.Output = path
+			config.NoColors = true
+
+			logger, err := NewLogger(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			logger.Log(context.Background(), notice, "configuration reloaded", "version", 3)
+			logger.Info("info suppressed")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+			output := string(data)
+			if !strings.Contains(output, "configuration reloaded") || !strings.Contains(output, tc.want) {
+				t.Errorf("Expected NOTICE line containing '%s', got: %s", tc.want, output)
+			}
+			if strings.Contains(output, "info suppressed") {
+				t.Errorf("Expected INFO to stay disabled, got: %s", output)
+			}
+		})
+	}
+}
+
 func TestDependencyInjection(t *testing.T) {
 	// Test dependency injection pattern
 	config := JsonConfig{
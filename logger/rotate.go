@@ -0,0 +1,380 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const megabyte = 1024 * 1024
+
+// Time-based rotation schedules accepted in JsonConfig.Rotate
+const (
+	rotateDaily  = "daily"
+	rotateHourly = "hourly"
+)
+
+// rotateOptions configures when a rotatingFile rotates and which rotated files it keeps.
+type rotateOptions struct {
+	maxSize    int64         // bytes; 0 disables size-based rotation
+	maxBackups int           // numbered backups to keep; 0 keeps all of them
+	every      string        // rotateDaily, rotateHourly or "" for no time-based rotation
+	utc        bool          // use UTC instead of local time for period boundaries and file names
+	maxAge     time.Duration // rotated files older than this are deleted; 0 keeps them forever
+	compress   bool          // gzip rotated files in the background
+}
+
+// rotatingFile is the writer behind every file output. Both the slog handler and the classic
+// log.Logger of a sink write through the same rotatingFile, so writes and rotations are
+// serialized and a line is never split across two files.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	rotateOptions
+	now func() time.Time
+
+	file        *os.File
+	size        int64
+	periodStart time.Time // start of the period the active file belongs to (time-based rotation only)
+	periodEnd   time.Time
+
+	compressSignal chan struct{} // wakes the compressor goroutine, nil unless compress is set
+	compressDone   chan struct{} // closed when the compressor goroutine exits
+	closed         bool
+}
+
+// newRotatingFile opens (or creates) path for appending and removes expired rotated files.
+func newRotatingFile(path string, opts rotateOptions) (*rotatingFile, error) {
+	w := &rotatingFile{
+		path:          path,
+		rotateOptions: opts,
+		now:           time.Now,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.removeExpired()
+	if w.compress {
+		w.compressSignal = make(chan struct{}, 1)
+		w.compressDone = make(chan struct{})
+		go w.compressLoop(w.compressSignal)
+		// pick up files rotated but left uncompressed by a previous run
+		w.requestCompression()
+	}
+	return w, nil
+}
+
+// Write appends p to the active file. It first rotates the file when a period boundary has been
+// crossed or when p would push it past maxSize.
+func (w *rotatingFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		// the owning logger was closed; late writes are dropped
+		return len(p), nil
+	}
+
+	var err error
+	if w.every != "" && !w.now().Before(w.periodEnd) {
+		err = w.rotateDated()
+	} else if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		err = w.rotate()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to rotate log file '%v' with error `%v`\n", w.path, err)
+	}
+	if w.file == nil {
+		return 0, fmt.Errorf("log file %s is not open", w.path)
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the active file to disk.
+func (w *rotatingFile) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Reopen closes the active file and opens w.path again, picking up a file recreated by an external
+// tool such as logrotate. Writes block on w.mu meanwhile, so every line lands whole in one of the
+// two files.
+func (w *rotatingFile) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	var closeErr error
+	if w.file != nil {
+		closeErr = w.file.Close()
+		w.file = nil
+	}
+	if err := w.open(); err != nil {
+		return fmt.Errorf("reopen %s: %w", w.path, err)
+	}
+	return closeErr
+}
+
+// Close closes the active file and waits for pending compressions to finish. Writes after Close
+// are discarded. Closing more than once is a no-op.
+func (w *rotatingFile) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	if w.compressSignal != nil {
+		close(w.compressSignal)
+		w.compressSignal = nil
+	}
+	w.mu.Unlock()
+
+	// the compressor needs w.mu to commit its last file, so wait outside the lock
+	if w.compressDone != nil {
+		<-w.compressDone
+	}
+	return err
+}
+
+// open opens the active file and records its current size. Caller must hold w.mu or own w exclusively.
+func (w *rotatingFile) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	if w.every != "" {
+		// an existing non-empty file belongs to the period it was last written in
+		since := w.now()
+		if w.size > 0 {
+			since = info.ModTime()
+		}
+		w.periodStart = w.startOfPeriod(since)
+		w.periodEnd = w.endOfPeriod(w.periodStart)
+	}
+	return nil
+}
+
+// rotate shifts app.log.N to app.log.N+1, renames the active file to app.log.1 and opens a
+// fresh one. Renames are atomic, so readers always see complete files. Caller must hold w.mu.
+func (w *rotatingFile) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	// every backup may exist plain or already gzipped, so both names are shifted
+	last := w.maxBackups
+	if last == 0 {
+		// keep everything: shift every existing backup up by one
+		for last = 1; fileExists(w.backupName(last)) || fileExists(w.backupName(last)+gzipExt); last++ {
+		}
+	} else {
+		for _, name := range []string{w.backupName(last), w.backupName(last) + gzipExt} {
+			if err := removeIfExists(name); err != nil {
+				return w.reopenAfter(err)
+			}
+		}
+	}
+	for i := last - 1; i >= 1; i-- {
+		for _, ext := range []string{"", gzipExt} {
+			if err := renameIfExists(w.backupName(i)+ext, w.backupName(i+1)+ext); err != nil {
+				return w.reopenAfter(err)
+			}
+		}
+	}
+	if err := os.Rename(w.path, w.backupName(1)); err != nil {
+		return w.reopenAfter(err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.removeExpired()
+	w.requestCompression()
+	return nil
+}
+
+// rotateDated renames the active file after the period it covers (app.log -> app-2026-10-16.log)
+// and opens a fresh one for the current period. Caller must hold w.mu.
+func (w *rotatingFile) rotateDated() error {
+	if w.size == 0 {
+		// nothing was written during the finished period, so there is nothing to keep
+		w.periodStart = w.startOfPeriod(w.now())
+		w.periodEnd = w.endOfPeriod(w.periodStart)
+		return nil
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	target := w.datedName(w.periodStart, 0)
+	for i := 1; fileExists(target) || fileExists(target+gzipExt); i++ {
+		target = w.datedName(w.periodStart, i)
+	}
+	if err := os.Rename(w.path, target); err != nil {
+		return w.reopenAfter(err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.removeExpired()
+	w.requestCompression()
+	return nil
+}
+
+// startOfPeriod truncates t to the start of its rotation period in the configured time zone.
+func (w *rotatingFile) startOfPeriod(t time.Time) time.Time {
+	if w.utc {
+		t = t.UTC()
+	} else {
+		t = t.Local()
+	}
+	if w.every == rotateHourly {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// endOfPeriod returns the boundary at which a period starting at start ends.
+func (w *rotatingFile) endOfPeriod(start time.Time) time.Time {
+	if w.every == rotateHourly {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// datedName returns the rotated name for the period starting at start, e.g. app-2026-10-16.log
+// (daily) or app-2026-10-16T15.log (hourly). A non-zero n disambiguates repeated rotations.
+func (w *rotatingFile) datedName(start time.Time, n int) string {
+	layout := "2006-01-02"
+	if w.every == rotateHourly {
+		layout = "2006-01-02T15"
+	}
+	ext := filepath.Ext(w.path)
+	name := strings.TrimSuffix(w.path, ext) + "-" + start.Format(layout)
+	if n > 0 {
+		name += "." + strconv.Itoa(n)
+	}
+	return name + ext
+}
+
+// rotatedFiles lists the numbered and dated files rotated out of w.path, compressed or not.
+func (w *rotatingFile) rotatedFiles() ([]string, error) {
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(w.path)
+	ext := filepath.Ext(base)
+	datedPrefix := strings.TrimSuffix(base, ext) + "-"
+
+	var files []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), gzipExt)
+		if entry.IsDir() || name == base {
+			continue
+		}
+		numbered := strings.HasPrefix(name, base+".") && isDigits(name[len(base)+1:])
+		dated := strings.HasPrefix(name, datedPrefix) && strings.HasSuffix(name, ext) &&
+			len(name) >= len(datedPrefix)+4 && isDigits(name[len(datedPrefix):len(datedPrefix)+4])
+		if numbered || dated {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// removeExpired deletes rotated files last modified more than maxAge ago. Caller must hold w.mu
+// or own w exclusively.
+func (w *rotatingFile) removeExpired() {
+	if w.maxAge <= 0 {
+		return
+	}
+	files, err := w.rotatedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list rotated log files for '%v' with error `%v`\n", w.path, err)
+		return
+	}
+	cutoff := w.now().Add(-w.maxAge)
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := removeIfExists(name); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove expired log file '%v' with error `%v`\n", name, err)
+		}
+	}
+}
+
+// reopenAfter reopens the active file after a failed rotation so logging can continue, and returns cause.
+func (w *rotatingFile) reopenAfter(cause error) error {
+	if err := w.open(); err != nil {
+		return fmt.Errorf("%w (reopen: %v)", cause, err)
+	}
+	return cause
+}
+
+// backupName returns the name of the i-th rotated file, e.g. app.log.1.
+func (w *rotatingFile) backupName(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func renameIfExists(from, to string) error {
+	if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_SizeRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	w, err := newRotatingFile(path, rotateOptions{maxSize: 20, maxBackups: 2})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first line 1234\n", "second line 123\n", "third line 1234\n", "fourth line 123\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "fourth line 123\n",
+		path + ".1": "third line 1234\n",
+		path + ".2": "second line 123\n",
+	}
+	for name, want := range expected {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("Expected %s to contain '%s', got '%s'", name, want, got)
+		}
+	}
+	if fileExists(path + ".3") {
+		t.Errorf("Expected at most 2 backups, found %s", path+".3")
+	}
+}
+
+func TestRotatingFile_SharedBySlogAndClassic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger, err := NewLogger(JsonConfig{
+		Levels:   "INFO",
+		Output:   path,
+		NoColors: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ml := logger.(*modernLogger)
+	if _, ok := ml.outputs[0].(*rotatingFile); !ok {
+		t.Fatalf("Expected file output to be a rotatingFile, got %T", ml.outputs[0])
+	}
+	if ml.configs[0].logger.Writer() != ml.outputs[0] {
+		t.Errorf("Expected classic logger to write through the sink's rotatingFile")
+	}
+
+	logger.Info("written through the rotating file")
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(got), "written through the rotating file") {
+		t.Errorf("Expected message in log file, got: %s", got)
+	}
+}
+
+func TestRotatingFile_DailyRotationAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	// a rotated file from long ago that should be swept, and an unrelated file that must survive
+	expired := filepath.Join(dir, "app-2020-01-01.log")
+	unrelated := filepath.Join(dir, "app-server.log")
+	for _, name := range []string{expired, unrelated} {
+		if err := os.WriteFile(name, []byte("old\n"), 0666); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(expired, old, old); err != nil {
+		t.Fatalf("Failed to age %s: %v", expired, err)
+	}
+
+	clock := time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC)
+	w, err := newRotatingFile(path, rotateOptions{every: rotateDaily, utc: true, maxAge: 7 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+	defer w.Close()
+	w.now = func() time.Time { return clock }
+	w.periodStart = w.startOfPeriod(clock)
+	w.periodEnd = w.endOfPeriod(w.periodStart)
+
+	if fileExists(expired) {
+		t.Errorf("Expected %s to be removed by the retention sweep", expired)
+	}
+	if !fileExists(unrelated) {
+		t.Errorf("Expected unrelated file %s to be kept", unrelated)
+	}
+
+	if _, err := w.Write([]byte("before midnight\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	clock = clock.Add(2 * time.Minute)
+	if _, err := w.Write([]byte("after midnight\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	rotated, err := os.ReadFile(filepath.Join(dir, "app-2026-10-16.log"))
+	if err != nil {
+		t.Fatalf("Expected dated file for the finished day: %v", err)
+	}
+	if string(rotated) != "before midnight\n" {
+		t.Errorf("Expected rotated file to contain 'before midnight', got '%s'", rotated)
+	}
+	active, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read active file: %v", err)
+	}
+	if string(active) != "after midnight\n" {
+		t.Errorf("Expected active file to contain 'after midnight', got '%s'", active)
+	}
+}
+
+func TestRotatingFile_Compression(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	// left uncompressed by a previous run
+	if err := os.WriteFile(path+".2", []byte("leftover\n"), 0666); err != nil {
+		t.Fatalf("Failed to create leftover backup: %v", err)
+	}
+
+	w, err := newRotatingFile(path, rotateOptions{maxSize: 10, compress: true})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"rotated 1\n", "active 22\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path + ".1" + gzipExt: "rotated 1\n",
+		path + ".2" + gzipExt: "leftover\n",
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for name, want := range expected {
+		for !fileExists(name) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := readGzip(t, name); got != want {
+			t.Errorf("Expected %s to contain '%s', got '%s'", name, want, got)
+		}
+	}
+}
+
+func readGzip(t *testing.T, name string) string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", name, err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to read gzip header of %s: %v", name, err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger, err := NewLogger(JsonConfig{
+		Levels:     "INFO",
+		Output:     path,
+		NoColors:   true,
+		Structured: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	stop := ReopenOnSignal(logger)
+	defer stop()
+
+	logger.Info("before logrotate")
+	// what logrotate does in create mode: move the file away and leave the process to recreate it
+	if err := os.Rename(path, path+".old"); err != nil {
+		t.Fatalf("Failed to move log file: %v", err)
+	}
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find own process: %v", err)
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !fileExists(path) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	logger.Info("after logrotate")
+
+	old, err := os.ReadFile(path + ".old")
+	if err != nil {
+		t.Fatalf("Failed to read moved log file: %v", err)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read reopened log file: %v", err)
+	}
+	if !strings.Contains(string(old), "before logrotate") || strings.Contains(string(old), "after logrotate") {
+		t.Errorf("Expected only 'before logrotate' in moved file, got: %s", old)
+	}
+	if !strings.Contains(string(current), "after logrotate") {
+		t.Errorf("Expected 'after logrotate' in reopened file, got: %s", current)
+	}
+}
@@ -4,18 +4,19 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"slices"
 	"strings"
 )
 
 // AddLogger creates a new logger configuration for internal use by modernLogger
 func AddLogger(logger LoggerConfig) (*LoggerConfig, error) {
 	var flags int
-	if slices.Contains(logger.Levels, DEBUG) {
+	if debugEnabled(logger.Levels) {
 		flags |= log.Lshortfile
 	}
 
-	if logger.Stdout {
+	if logger.output != nil {
+		logger.logger = log.New(logger.output, "", flags)
+	} else if logger.Stdout {
 		logger.logger = log.New(os.Stdout, "", flags)
 	} else {
 		file, err := os.OpenFile(logger.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
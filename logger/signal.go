@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ReopenOnSignal reopens the file outputs of logger every time one of signals is received, which
+// lets external tools like logrotate (in "create" mode) move log files away. With no signals it
+// listens for SIGHUP. Call the returned function to stop watching.
+//
+//	stop := logger.ReopenOnSignal(log)
+//	defer stop()
+func ReopenOnSignal(logger Logger, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	received := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(received, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-received:
+				if err := logger.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to reopen log outputs with error `%v`\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SpanContext identifies the trace and span a record was logged in, as carried by a W3C
+// traceparent header: hex encoded trace and span IDs plus the trace flags.
+type SpanContext struct {
+	TraceID    string // 32 lowercase hex digits
+	SpanID     string // 16 lowercase hex digits
+	TraceFlags byte   // bit 0 is the sampled flag
+}
+
+// IsValid reports whether the trace and span IDs are well-formed and non-zero.
+func (sc SpanContext) IsValid() bool {
+	return isTraceHex(sc.TraceID, 32) && isTraceHex(sc.SpanID, 16)
+}
+
+// Sampled reports whether the caller recorded the trace.
+func (sc SpanContext) Sampled() bool {
+	return sc.TraceFlags&0x01 != 0
+}
+
+// attrs returns the fields logged for the span: trace_id, span_id and trace_flags.
+func (sc SpanContext) attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("trace_id", sc.TraceID),
+		slog.String("span_id", sc.SpanID),
+		slog.String("trace_flags", fmt.Sprintf("%02x", sc.TraceFlags)),
+	}
+}
+
+// SpanContextProvider looks up the span active in a context. Implement it to correlate logs with a
+// tracing library without this package depending on it, eg. for OpenTelemetry:
+//
+//	type otelSpans struct{}
+//
+//	func (otelSpans) SpanContext(ctx context.Context) (logger.SpanContext, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return logger.SpanContext{
+//			TraceID:    sc.TraceID().String(),
+//			SpanID:     sc.SpanID().String(),
+//			TraceFlags: byte(sc.TraceFlags()),
+//		}, sc.IsValid()
+//	}
+//
+// and add TraceExtractor(otelSpans{}) to JsonConfig.ContextExtractors.
+type SpanContextProvider interface {
+	SpanContext(ctx context.Context) (SpanContext, bool)
+}
+
+// TraceExtractor returns a ContextExtractor adding the trace_id, span_id and trace_flags of the
+// span provider finds in the context.
+func TraceExtractor(provider SpanContextProvider) ContextExtractor {
+	return func(ctx context.Context) []slog.Attr {
+		sc, ok := provider.SpanContext(ctx)
+		if !ok || !sc.IsValid() {
+			return nil
+		}
+		return sc.attrs()
+	}
+}
+
+// spanContextKey is the context key ContextWithSpanContext stores a SpanContext under.
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc. Every line logged with it through a
+// *Context method gets trace_id, span_id and trace_flags fields.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// ContextWithTraceparent parses a W3C traceparent header (eg. from an incoming request) and stores
+// the span context in a copy of ctx, see ContextWithSpanContext.
+func ContextWithTraceparent(ctx context.Context, traceparent string) (context.Context, error) {
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx, err
+	}
+	return ContextWithSpanContext(ctx, sc), nil
+}
+
+// SpanContextFromContext returns the span context stored in ctx by ContextWithSpanContext or
+// ContextWithTraceparent.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// ParseTraceparent parses a W3C traceparent header: version-traceid-parentid-flags, eg.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". Fields added by future versions are
+// ignored.
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	fields := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(fields) < 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent: %s", traceparent)
+	}
+	version, traceID, spanID, flags := fields[0], fields[1], fields[2], fields[3]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" || (version == "00" && len(fields) != 4) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent version: %s", traceparent)
+	}
+	if len(flags) != 2 || !isLowerHex(flags) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent flags: %s", traceparent)
+	}
+	sc := SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: hexByte(flags)}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent ids: %s", traceparent)
+	}
+	return sc, nil
+}
+
+// spanAttrs returns the fields of the span context stored in ctx, if any.
+func spanAttrs(ctx context.Context) []slog.Attr {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok || !sc.IsValid() {
+		return nil
+	}
+	return sc.attrs()
+}
+
+// isTraceHex reports whether id is n lowercase hex digits, not all zero (which W3C reserves as invalid).
+func isTraceHex(id string, n int) bool {
+	return len(id) == n && isLowerHex(id) && strings.Trim(id, "0") != ""
+}
+
+func isLowerHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// hexByte decodes two lowercase hex digits.
+func hexByte(s string) byte {
+	var b byte
+	for _, r := range s {
+		b <<= 4
+		if r >= 'a' {
+			b |= byte(r-'a') + 10
+		} else {
+			b |= byte(r - '0')
+		}
+	}
+	return b
+}
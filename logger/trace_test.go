@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(testTraceparent)
+	if err != nil {
+		t.Fatalf("Failed to parse traceparent: %v", err)
+	}
+	if sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID != "00f067aa0ba902b7" || !sc.Sampled() {
+		t.Errorf("Unexpected span context: %+v", sc)
+	}
+
+	// a future version may append fields
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Errorf("Expected future version to parse, got: %v", err)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",          // missing flags
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",       // forbidden version
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", // version 00 has four fields
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",       // zero trace ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",       // zero span ID
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",       // uppercase
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",        // short trace ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",       // bad flags
+	}
+	for _, header := range invalid {
+		if _, err := ParseTraceparent(header); err == nil {
+			t.Errorf("Expected '%s' to be rejected", header)
+		}
+	}
+}
+
+type testSpanProvider struct {
+	sc SpanContext
+}
+
+func (p testSpanProvider) SpanContext(ctx context.Context) (SpanContext, bool) {
+	return p.sc, true
+}
+
+func TestModernLogger_TraceCorrelation(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "json.log")
+	classicPath := filepath.Join(dir, "classic.log")
+
+	ctx, err := ContextWithTraceparent(context.Background(), testTraceparent)
+	if err != nil {
+		t.Fatalf("Failed to store traceparent: %v", err)
+	}
+
+	logger, err := NewLogger(JsonConfig{Levels: "INFO", Output: jsonPath, Json: true})
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	defer logger.Close()
+	logger.WithGroup("db").InfoContext(ctx, "json line", "table", "users")
+
+	got, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(got, &entry); err != nil {
+		t.Fatalf("Expected one JSON line, got: %s", got)
+	}
+	if entry["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || entry["span_id"] != "00f067aa0ba902b7" || entry["trace_flags"] != "01" {
+		t.Errorf("Expected top-level trace fields, got: %s", got)
+	}
+	if source, _ := entry["source"].(map[string]any); source["file"] != "trace_test.go" {
+		t.Errorf("Expected the caller as source, got: %s", got)
+	}
+
+	// spans from a tracing library come in through a provider
+	provider := testSpanProvider{sc: SpanContext{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331"}}
+	classic, err := NewLogger(JsonConfig{
+		Levels:            "INFO",
+		Output:            classicPath,
+		NoColors:          true,
+		ContextExtractors: []ContextExtractor{TraceExtractor(provider)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create classic logger: %v", err)
+	}
+	defer classic.Close()
+	classic.InfoContext(ctx, "from traceparent")
+	classic.InfoContext(context.Background(), "from provider")
+
+	got, err = os.ReadFile(classicPath)
+	if err != nil {
+		t.Fatalf("Failed to read classic log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), got)
+	}
+	// the provider's span takes precedence over the stored traceparent, and each field is written once
+	if !strings.HasSuffix(lines[0], "from traceparent trace_id=0af7651916cd43dd8448eb211c80319c span_id=b7ad6b7169203331 trace_flags=00") ||
+		strings.Count(lines[0], "trace_id=") != 1 {
+		t.Errorf("Expected a single trace suffix from the provider, got: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "from provider trace_id=0af7651916cd43dd8448eb211c80319c span_id=b7ad6b7169203331 trace_flags=00") {
+		t.Errorf("Expected trace suffix from the provider, got: %s", lines[1])
+	}
+}
@@ -16,6 +16,7 @@ const (
 	WARNING  LogLevel = 3
 	INFO     LogLevel = 4
 	DEBUG    LogLevel = 5
+	TRACE    LogLevel = 6
 	API      LogLevel = 10
 	// COLORS
 	RED    = "\033[31m"
@@ -39,6 +40,7 @@ type levelConsts struct {
 	ERROR    string
 	WARNING  string
 	DEBUG    string
+	TRACE    string
 	API      string
 	DISABLED string
 }
@@ -49,12 +51,14 @@ var levels = levelConsts{
 	ERROR:    "ERROR",
 	WARNING:  "WARN ", // with consistent space padding
 	DEBUG:    "DEBUG",
+	TRACE:    "TRACE",
 	DISABLED: "DISABLED",
 	API:      "API",
 }
 
 // stringToLevel maps string representation to LogLevel
 var stringToLevel = map[string]LogLevel{
+	"TRACE":    TRACE,
 	"DEBUG":    DEBUG,
 	"INFO ":    INFO, // with consistent space padding
 	"ERROR":    ERROR,
@@ -84,6 +88,11 @@ func logApiMessage(statusCode int, message string, modernLog func()) {
 
 // --- Sprintf-style logging functions ---
 
+func Tracef(format string, a ...interface{}) {
+	messageToSend := fmt.Sprintf(format, a...)
+	logMessage(levels.TRACE, messageToSend, func(msg string) { globalLogger.Tracef(format, a...) })
+}
+
 func Debugf(format string, a ...interface{}) {
 	messageToSend := fmt.Sprintf(format, a...)
 	logMessage(levels.DEBUG, messageToSend, func(msg string) { globalLogger.Debugf(format, a...) })
@@ -131,6 +140,10 @@ func sprintArgs(a ...interface{}) string {
 	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
 }
 
+func Trace(a ...interface{}) {
+	logMessage(levels.TRACE, sprintArgs(a...), func(msg string) { globalLogger.Tracef("%s", msg) })
+}
+
 func Debug(a ...interface{}) {
 	logMessage(levels.DEBUG, sprintArgs(a...), func(msg string) { globalLogger.Debugf("%s", msg) })
 }
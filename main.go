@@ -85,6 +85,10 @@ func modernExample() {
 		ApiLevels: "INFO,ERROR,WARNING",
 		NoColors:  false,
 		Json:      false, // Set to true for JSON output
+		// Add the request ID stored in the context to every *Context log line
+		ContextExtractors: []logger.ContextExtractor{
+			logger.ContextKey(requestIDKey, "request_id"),
+		},
 	}
 
 	log, err := logger.NewLogger(config)